@@ -0,0 +1,300 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+// Package addrbook 地址簿：标签到账户地址的映射。
+//
+// 登记的地址都经过校验（格式、校验码和可选的前缀限定），
+// 同一地址不会以不同标签重复登记（不同校验方案的写法视为同一地址）。
+package addrbook
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/cxio/cbase"
+	"github.com/cxio/cbase/cerror"
+	"github.com/cxio/cbase/paddr"
+)
+
+var (
+	// 标签为空。
+	ErrLabel = cerror.New(9001, "地址标签为空")
+
+	// 标签重复。
+	ErrDupLabel = cerror.New(9002, "地址标签已存在")
+
+	// 地址重复。
+	ErrDupAddress = cerror.New(9003, "地址已登记")
+
+	// 前缀不符。
+	ErrPrefix = cerror.New(9004, "地址前缀不符")
+
+	// 标签不存在。
+	ErrNotFound = cerror.New(9005, "地址标签不存在")
+)
+
+// Entry 地址簿条目。
+type Entry struct {
+	Label   string `json:"label"`
+	Address string `json:"address"`
+	Note    string `json:"note,omitempty"`
+}
+
+// Problem 导入时发现的问题条目。
+type Problem struct {
+	Index int   // 在导入数据中的序位
+	Entry Entry // 条目
+	Err   error // 问题
+}
+
+// Error 问题描述。
+func (p *Problem) Error() string {
+	return p.Entry.Label + "（" + p.Entry.Address + "）：" + p.Err.Error()
+}
+
+// Unwrap 获取原始错误。
+func (p *Problem) Unwrap() error {
+	return p.Err
+}
+
+// 已登记的条目。
+type item struct {
+	Entry
+	pkh    paddr.PKAddr
+	prefix string
+}
+
+// Book 地址簿。
+// 可安全地并发使用。
+type Book struct {
+	mu     sync.RWMutex
+	prefix string           // 限定的前缀，空值表示不限
+	labels map[string]*item // 标签索引
+	addrs  map[string]*item // 地址索引（前缀+公钥地址）
+}
+
+// New 创建地址簿。
+// prefix 非空时，只接受该前缀（网络）的地址。
+func New(prefix string) *Book {
+	return &Book{
+		prefix: prefix,
+		labels: make(map[string]*item),
+		addrs:  make(map[string]*item),
+	}
+}
+
+// Prefix 限定的地址前缀。
+func (b *Book) Prefix() string {
+	return b.prefix
+}
+
+// Len 条目数。
+func (b *Book) Len() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return len(b.labels)
+}
+
+// Add 添加条目。
+// 标签不可为空或重复，地址须有效、符合前缀限定且尚未登记。
+func (b *Book) Add(label, addr, note string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.add(Entry{Label: label, Address: addr, Note: note})
+}
+
+// Remove 移除条目。
+func (b *Book) Remove(label string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	it, ok := b.labels[label]
+	if !ok {
+		return ErrNotFound.With(label)
+	}
+	delete(b.labels, label)
+	delete(b.addrs, addrKey(it.prefix, it.pkh))
+
+	return nil
+}
+
+// Get 按标签获取条目。
+func (b *Book) Get(label string) (Entry, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if it, ok := b.labels[label]; ok {
+		return it.Entry, true
+	}
+	return Entry{}, false
+}
+
+// Resolve 按标签获取公钥地址和前缀。
+// 供钱包构造支付输出使用。
+func (b *Book) Resolve(label string) (paddr.PKAddr, string, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	it, ok := b.labels[label]
+	if !ok {
+		return nil, "", ErrNotFound.With(label)
+	}
+	return it.pkh, it.prefix, nil
+}
+
+// LabelOf 查询地址的标签。
+// 地址的任一校验方案写法均可查到，未登记或无效时返回false。
+func (b *Book) LabelOf(addr string) (string, bool) {
+	pkh, prefix, err := paddr.Decode(addr)
+	if err != nil {
+		return "", false
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if it, ok := b.addrs[addrKey(prefix, pkh)]; ok {
+		return it.Label, true
+	}
+	return "", false
+}
+
+// List 全部条目。
+// 按标签排序。
+func (b *Book) List() []Entry {
+	return b.Search("")
+}
+
+// Search 按标签前缀搜索。
+// 不区分大小写，结果按标签排序。
+func (b *Book) Search(prefix string) []Entry {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	prefix = strings.ToLower(prefix)
+	var out []Entry
+
+	for label, it := range b.labels {
+		if strings.HasPrefix(strings.ToLower(label), prefix) {
+			out = append(out, it.Entry)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Label < out[j].Label })
+
+	return out
+}
+
+// Import 从JSON数组导入条目。
+// 有效的条目被添加，无效或重复的条目作为问题返回（不中止导入）。
+// 仅在数据无法解析时返回错误。
+func (b *Book) Import(r io.Reader) ([]*Problem, error) {
+	var list []Entry
+
+	if err := json.NewDecoder(r).Decode(&list); err != nil {
+		return nil, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var out []*Problem
+
+	for i, e := range list {
+		if err := b.add(e); err != nil {
+			out = append(out, &Problem{Index: i, Entry: e, Err: err})
+		}
+	}
+	return out, nil
+}
+
+// Export 导出为JSON数组。
+// 按标签排序，格式与 Import 一致。
+func (b *Book) Export(w io.Writer) error {
+	list := b.List()
+	if list == nil {
+		list = []Entry{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(list)
+}
+
+// Load 从文件载入地址簿。
+// 文件不存在时返回空地址簿，文件中有问题条目时返回首个问题。
+func Load(path, prefix string) (*Book, error) {
+	b := New(prefix)
+
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return b, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	ps, err := b.Import(f)
+	if err != nil {
+		return nil, err
+	}
+	if len(ps) > 0 {
+		return nil, ps[0]
+	}
+	return b, nil
+}
+
+// Save 保存地址簿到文件。
+// 原子地替换目标文件。
+func (b *Book) Save(path string) error {
+	var buf strings.Builder
+
+	if err := b.Export(&buf); err != nil {
+		return err
+	}
+	return cbase.WriteFileAtomic(path, []byte(buf.String()), 0644)
+}
+
+//
+// 私有辅助
+///////////////////////////////////////////////////////////////////////////////
+
+// 添加条目（已加锁）。
+func (b *Book) add(e Entry) error {
+	e.Address = strings.TrimSpace(e.Address)
+
+	if strings.TrimSpace(e.Label) == "" {
+		return ErrLabel
+	}
+	if _, ok := b.labels[e.Label]; ok {
+		return ErrDupLabel.With(e.Label)
+	}
+	pkh, prefix, err := paddr.Decode(e.Address)
+	if err != nil {
+		return err
+	}
+	if b.prefix != "" && prefix != b.prefix {
+		return ErrPrefix.With(prefix, b.prefix)
+	}
+	key := addrKey(prefix, pkh)
+
+	if it, ok := b.addrs[key]; ok {
+		return ErrDupAddress.With(it.Label)
+	}
+	it := &item{Entry: e, pkh: pkh, prefix: prefix}
+	b.labels[e.Label] = it
+	b.addrs[key] = it
+
+	return nil
+}
+
+// 地址索引键。
+// 前缀不含分隔符，故以分隔符连接不会混淆。
+func addrKey(prefix string, pkh []byte) string {
+	return prefix + string(paddr.Delimiter) + string(pkh)
+}
@@ -0,0 +1,106 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package addrbook
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cxio/cbase/paddr"
+)
+
+var (
+	pkh1 = bytes.Repeat([]byte{1}, paddr.HashSize)
+	pkh2 = bytes.Repeat([]byte{2}, paddr.HashSize)
+)
+
+func TestAdd(t *testing.T) {
+	b := New("cx")
+	a1 := paddr.Encode(pkh1, "cx")
+
+	if err := b.Add("Alice", a1, "friend"); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		label, addr string
+		want        error
+	}{
+		{"", paddr.Encode(pkh2, "cx"), ErrLabel},
+		{"Alice", paddr.Encode(pkh2, "cx"), ErrDupLabel},
+		{"Bob", paddr.Encode(pkh2, "tx"), ErrPrefix},
+		{"Bob", a1[:len(a1)-1] + "z", paddr.ErrChecksum},
+		{"Bob", "nodelimiter", paddr.ErrDelimMissing},
+	}
+	for _, tt := range tests {
+		if err := b.Add(tt.label, tt.addr, ""); !errors.Is(err, tt.want) {
+			t.Errorf("Add(%q, %q): got %v, want %v", tt.label, tt.addr, err, tt.want)
+		}
+	}
+	// 其它校验方案的写法视为同一地址
+	a1v2, _ := paddr.EncodeVer(pkh1, "cx", paddr.CheckV2)
+	if err := b.Add("Alice2", a1v2, ""); !errors.Is(err, ErrDupAddress) {
+		t.Errorf("v2 duplicate: got %v", err)
+	}
+	if label, ok := b.LabelOf(a1v2); !ok || label != "Alice" {
+		t.Errorf("LabelOf: got %q, %v", label, ok)
+	}
+	pkh, prefix, err := b.Resolve("Alice")
+	if err != nil || !bytes.Equal(pkh, pkh1) || prefix != "cx" {
+		t.Errorf("Resolve: got %x, %q, %v", pkh, prefix, err)
+	}
+	if err := b.Remove("Alice"); err != nil || b.Len() != 0 {
+		t.Errorf("Remove: %v, len %d", err, b.Len())
+	}
+}
+
+func TestSearch(t *testing.T) {
+	b := New("")
+	b.Add("alice", paddr.Encode(pkh1, "cx"), "")
+	b.Add("Albert", paddr.Encode(pkh2, "cx"), "")
+	b.Add("bob", paddr.Encode(pkh2, "tx"), "")
+
+	got := b.Search("AL")
+	if len(got) != 2 || got[0].Label != "Albert" || got[1].Label != "alice" {
+		t.Errorf("Search: got %+v", got)
+	}
+	if n := len(b.List()); n != 3 {
+		t.Errorf("List: got %d entries, want 3", n)
+	}
+}
+
+func TestImportExport(t *testing.T) {
+	good := paddr.Encode(pkh1, "cx")
+	data := `[
+		{"label": "alice", "address": "` + good + `"},
+		{"label": "alias", "address": "` + good + `"},
+		{"label": "broken", "address": "` + good[:len(good)-2] + `"}
+	]`
+	b := New("cx")
+
+	ps, err := b.Import(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ps) != 2 || ps[0].Index != 1 || !errors.Is(ps[0].Err, ErrDupAddress) || ps[1].Index != 2 {
+		t.Errorf("problems: %v", ps)
+	}
+	path := filepath.Join(t.TempDir(), "book.json")
+	if err := b.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Load(path, "cx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, ok := got.Get("alice"); !ok || e.Address != good {
+		t.Errorf("Load: got %+v", got.List())
+	}
+	// 前缀限定不符的文件无法载入
+	if _, err := Load(path, "tx"); !errors.Is(err, ErrPrefix) {
+		t.Errorf("Load with other prefix: got %v", err)
+	}
+}
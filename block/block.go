@@ -0,0 +1,85 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+// Package block 区块结构及其基本操作。
+package block
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+
+	"github.com/cxio/cbase/cerror"
+	"github.com/cxio/cbase/chash"
+	"github.com/cxio/cbase/tx"
+)
+
+// 区块哈希长度。
+const HashSize = 32
+
+var (
+	// 哈希文本无效。
+	ErrHashText = cerror.New(8001, "区块哈希文本格式无效")
+)
+
+// Hash 区块哈希。
+type Hash [HashSize]byte
+
+// String 十六进制表示。
+func (h Hash) String() string {
+	return hex.EncodeToString(h[:])
+}
+
+// MarshalText 编码为十六进制文本。
+func (h Hash) MarshalText() ([]byte, error) {
+	return []byte(h.String()), nil
+}
+
+// UnmarshalText 从十六进制文本解码。
+func (h *Hash) UnmarshalText(text []byte) error {
+	if hex.DecodedLen(len(text)) != HashSize {
+		return ErrHashText.With(string(text))
+	}
+	if _, err := hex.Decode(h[:], text); err != nil {
+		return ErrHashText.With(string(text))
+	}
+	return nil
+}
+
+// Block 区块。
+// 区块哈希由区块头计算，区块头包含全部交易ID的汇总哈希。
+type Block struct {
+	Height int      // 区块高度
+	Prev   Hash     // 前一区块哈希
+	Time   int64    // 出块时间戳（毫秒）
+	Txs    []*tx.Tx // 交易集
+}
+
+// TxRoot 交易ID集的汇总哈希。
+// 按交易顺序串接全部交易ID后计算。
+func (b *Block) TxRoot() []byte {
+	buf := make([]byte, 0, len(b.Txs)*tx.TxIDSize)
+
+	for _, t := range b.Txs {
+		id := t.ID()
+		buf = append(buf, id[:]...)
+	}
+	return chash.Sum256(1, buf)
+}
+
+// Header 区块头序列化。
+// 格式：高度（4）+ 前一区块哈希（32）+ 时间戳（8）+ 交易汇总哈希（32）。
+func (b *Block) Header() []byte {
+	buf := make([]byte, 0, 4+HashSize+8+HashSize)
+
+	buf = binary.BigEndian.AppendUint32(buf, uint32(b.Height))
+	buf = append(buf, b.Prev[:]...)
+	buf = binary.BigEndian.AppendUint64(buf, uint64(b.Time))
+
+	return append(buf, b.TxRoot()...)
+}
+
+// Hash 计算区块哈希。
+func (b *Block) Hash() (h Hash) {
+	copy(h[:], chash.Sum256(1, b.Header()))
+	return
+}
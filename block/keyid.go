@@ -0,0 +1,57 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package block
+
+import (
+	"github.com/cxio/cbase"
+	"github.com/cxio/cbase/tx"
+)
+
+// Output 区块中的输出及其脚本ID。
+type Output struct {
+	KeyID tx.Vin  // 脚本ID，即花费时的输入项
+	TxID  tx.TxID // 所属交易
+	Tx    int     // 交易在区块中的序位
+	Index int     // 输出在交易中的序位
+	Vout  tx.Vout // 输出项
+}
+
+// KeyID 区块中第 n 笔交易第 i 个输出的脚本ID。
+// 由区块高度、交易序位和输出序位构成（cbase.KeyID），各节点据此一致地引用输出。
+func (b *Block) KeyID(n, i int) (id tx.Vin) {
+	copy(id[:], cbase.KeyID(b.Height, n, i))
+	return
+}
+
+// Outputs 区块中的全部输出。
+// 按交易顺序及交易内的输出顺序排列，证据类输出也占用序位。
+// 注：输出序位以2字节存储，单笔交易超过 65536 个输出时后面的无法被唯一引用。
+func (b *Block) Outputs() []Output {
+	var out []Output
+
+	for n, t := range b.Txs {
+		id := t.ID()
+		for i, v := range t.Body.Vouts() {
+			out = append(out, Output{KeyID: b.KeyID(n, i), TxID: id, Tx: n, Index: i, Vout: v})
+		}
+	}
+	return out
+}
+
+// Lookup 按脚本ID查找区块中的输出。
+// 脚本ID不属于本区块（高度不符、序位越界或格式不规范）时返回false。
+func (b *Block) Lookup(id tx.Vin) (Output, bool) {
+	h, n, i := cbase.SplitKeyID(id[:])
+
+	if h != b.Height || n >= len(b.Txs) || b.KeyID(n, i) != id {
+		return Output{}, false
+	}
+	t := b.Txs[n]
+	vouts := t.Body.Vouts()
+
+	if i >= len(vouts) {
+		return Output{}, false
+	}
+	return Output{KeyID: id, TxID: t.ID(), Tx: n, Index: i, Vout: vouts[i]}, true
+}
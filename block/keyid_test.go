@@ -0,0 +1,64 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package block_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cxio/cbase"
+	"github.com/cxio/cbase/block"
+	"github.com/cxio/cbase/tx"
+)
+
+// 测试区块：两笔交易，分别有2个和1个输出。
+func testBlock() *block.Block {
+	pkh := bytes.Repeat([]byte{5}, 20)
+	mk := func(n int) *tx.Tx {
+		var outs []tx.Vout
+		for i := 0; i < n; i++ {
+			outs = append(outs, tx.NewCoinOut(&tx.Coin{Receiver: pkh, Amount: int64(n*10 + i)}))
+		}
+		return tx.New(tx.Header{Version: tx.Version, Minter: pkh}, tx.NewBody(nil, outs))
+	}
+	return &block.Block{Height: 42, Txs: []*tx.Tx{mk(2), mk(1)}}
+}
+
+func TestOutputs(t *testing.T) {
+	b := testBlock()
+	outs := b.Outputs()
+
+	want := [][2]int{{0, 0}, {0, 1}, {1, 0}}
+	if len(outs) != len(want) {
+		t.Fatalf("got %d outputs", len(outs))
+	}
+	for k, o := range outs {
+		h, n, i := cbase.SplitKeyID(o.KeyID[:])
+		if h != 42 || n != want[k][0] || i != want[k][1] || o.Tx != n || o.Index != i {
+			t.Errorf("output %d: KeyID (%d, %d, %d)", k, h, n, i)
+		}
+		got, ok := b.Lookup(o.KeyID)
+		if !ok || got.TxID != o.TxID || got.Vout.Coin() != o.Vout.Coin() {
+			t.Errorf("Lookup(%s) failed", o.KeyID)
+		}
+	}
+}
+
+func TestLookupMiss(t *testing.T) {
+	b := testBlock()
+	bad := []tx.Vin{
+		b.KeyID(2, 0), // 交易越界
+		b.KeyID(1, 1), // 输出越界
+		(&block.Block{Height: 41}).KeyID(0, 0),
+	}
+	tail := b.KeyID(0, 0)
+	tail[15] = 1 // 末尾非零
+	bad = append(bad, tail)
+
+	for _, id := range bad {
+		if _, ok := b.Lookup(id); ok {
+			t.Errorf("Lookup(%s): unexpected hit", id)
+		}
+	}
+}
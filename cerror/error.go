@@ -0,0 +1,126 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+// Package cerror 带错误码的错误类型及错误目录。
+//
+// 错误消息在 Error() 调用时才经由 locale 翻译，因此应用可以在包初始化之后再设置语言。
+// 错误码稳定不变，调用者可以按码识别错误，而不必依赖消息文本。
+//
+// 错误码分段：
+//
+//	100-999     cbase
+//	1000-1999   paddr
+//	2000-2999   tx
+//	3000-3999   qrcode
+//	4000-4999   vanity
+//	5000-5999   proof
+//	6000-6999   shamir
+//	7000-7999   wallet
+//	8000-8999   block
+//	9000-9999   addrbook
+//	10000-10999 mempool
+//	11000-11999 store
+package cerror
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/cxio/locale"
+)
+
+// Code 错误码。
+type Code uint32
+
+// Error 带错误码的错误。
+// 同码的错误在 errors.Is 中视为相同，无论参数是否一致。
+type Error struct {
+	Code   Code   // 错误码
+	Text   string // 消息原文（即翻译键）
+	Params []any  // 参数，附加在消息之后
+}
+
+// 错误目录。
+var catalogue = struct {
+	sync.RWMutex
+	m map[Code]*Error
+}{m: make(map[Code]*Error)}
+
+// New 创建并登记一个错误。
+// 通常用于定义包级的哨兵错误，错误码重复时抛出异常。
+func New(code Code, text string) *Error {
+	catalogue.Lock()
+	defer catalogue.Unlock()
+
+	if e, ok := catalogue.m[code]; ok {
+		panic(fmt.Sprintf("cerror: duplicate code %d (%q, %q)", code, e.Text, text))
+	}
+	e := &Error{Code: code, Text: text}
+	catalogue.m[code] = e
+
+	return e
+}
+
+// Lookup 按错误码查找已登记的错误。
+// 未登记时返回nil。
+func Lookup(code Code) *Error {
+	catalogue.RLock()
+	defer catalogue.RUnlock()
+
+	return catalogue.m[code]
+}
+
+// List 获取全部已登记的错误。
+// 按错误码升序排列。
+func List() []*Error {
+	catalogue.RLock()
+	out := make([]*Error, 0, len(catalogue.m))
+
+	for _, e := range catalogue.m {
+		out = append(out, e)
+	}
+	catalogue.RUnlock()
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Code < out[j].Code })
+	return out
+}
+
+// CodeOf 获取错误链中首个编码错误的错误码。
+// 无编码错误时返回0。
+func CodeOf(err error) Code {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return 0
+}
+
+// With 创建附带参数的同码错误。
+// 原错误不受影响。
+func (e *Error) With(params ...any) *Error {
+	return &Error{Code: e.Code, Text: e.Text, Params: params}
+}
+
+// Error 返回翻译后的消息。
+// 有参数时附加在消息之后，以冒号分隔。
+func (e *Error) Error() string {
+	msg := locale.GetText(e.Text)
+	if len(e.Params) == 0 {
+		return msg
+	}
+	ps := make([]string, len(e.Params))
+
+	for i, p := range e.Params {
+		ps[i] = fmt.Sprint(p)
+	}
+	return msg + "：" + strings.Join(ps, ", ")
+}
+
+// Is 同码即为相同错误。
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
@@ -0,0 +1,46 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package cerror_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/cxio/cbase/cerror"
+)
+
+var errTest = cerror.New(99901, "测试错误")
+
+func TestIs(t *testing.T) {
+	e := errTest.With(42, "x")
+
+	if !errors.Is(e, errTest) {
+		t.Error("errors.Is: derived error does not match sentinel")
+	}
+	if !errors.Is(fmt.Errorf("wrap: %w", e), errTest) {
+		t.Error("errors.Is: wrapped error does not match sentinel")
+	}
+	if got := e.Error(); got != "测试错误：42, x" {
+		t.Errorf("Error: got %q", got)
+	}
+	if errTest.Params != nil {
+		t.Error("With: sentinel modified")
+	}
+}
+
+func TestCatalogue(t *testing.T) {
+	if cerror.Lookup(99901) != errTest {
+		t.Error("Lookup: sentinel not registered")
+	}
+	if got := cerror.CodeOf(fmt.Errorf("wrap: %w", errTest)); got != 99901 {
+		t.Errorf("CodeOf: got %d", got)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("New: duplicate code accepted")
+		}
+	}()
+	cerror.New(99901, "重复")
+}
@@ -6,6 +6,8 @@ package chash
 
 import (
 	"crypto/sha256"
+	"hash"
+	"io"
 
 	"golang.org/x/crypto/blake2b"
 )
@@ -76,3 +78,56 @@ func Sum256(ver int, data []byte) []byte {
 	b := blake2b.Sum256(data)
 	return b[:]
 }
+
+//
+// 流式计算
+///////////////////////////////////////////////////////////////////////////////
+
+// NewBlake 创建BLAKE2b哈希器。
+// size 为输出字节数（1-64），key 为可选密钥（<=64）。
+// 用于流式数据，与 BlakeSum* 系列的结果一致。
+func NewBlake(size int, key []byte) (hash.Hash, error) {
+	return blake2b.New(size, key)
+}
+
+// BlakeReader 流式BLAKE2b哈希计算。
+// pfix 为哈希前置的命名字节序列，通常为nil。
+// 与 BlakeSum160/192/224 对应，size 分别取 Size160/Size192/Size224。
+func BlakeReader(size int, r io.Reader, key, pfix []byte) ([]byte, error) {
+	h, err := blake2b.New(size, key)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = io.Copy(h, r); err != nil {
+		return nil, err
+	}
+	return h.Sum(pfix), nil
+}
+
+// Sum160Reader 流式160位哈希运算。
+// 结果同 Sum160。
+// 因密钥源自全部数据的SHA2哈希，需要读取两遍，故要求可回读。
+func Sum160Reader(ver int, r io.ReadSeeker) ([]byte, error) {
+	// ver: 1
+	start, err := r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	if _, err = io.Copy(h, r); err != nil {
+		return nil, err
+	}
+	k := h.Sum(nil)
+
+	if _, err = r.Seek(start, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return BlakeReader(Size160, r, k[:Size160], nil)
+}
+
+// Sum256Reader 流式256位哈希运算。
+// 结果同 Sum256。
+func Sum256Reader(ver int, r io.Reader) ([]byte, error) {
+	// ver: 1
+	return BlakeReader(blake2b.Size256, r, nil, nil)
+}
@@ -0,0 +1,109 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package chash
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+// 测试用数据集（含空数据）。
+var testData = [][]byte{
+	nil,
+	{},
+	[]byte("a"),
+	[]byte("hello, world"),
+	bytes.Repeat([]byte{0x5a}, 70000),
+}
+
+func TestBlakeReader(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	pfix := []byte("cx-")
+
+	tests := []struct {
+		name string
+		size int
+		key  []byte
+		pfix []byte
+		sum  func([]byte) []byte
+	}{
+		{"224", Size224, nil, nil, BlakeSum224},
+		{"192", Size192, nil, nil, func(d []byte) []byte { return BlakeSum192(d, nil, nil) }},
+		{"192/key+pfix", Size192, key, pfix, func(d []byte) []byte { return BlakeSum192(d, key, pfix) }},
+		{"160", Size160, nil, nil, func(d []byte) []byte { return BlakeSum160(d, nil, nil) }},
+		{"160/key", Size160, key, nil, func(d []byte) []byte { return BlakeSum160(d, key, nil) }},
+		{"160/key+pfix", Size160, key, pfix, func(d []byte) []byte { return BlakeSum160(d, key, pfix) }},
+	}
+	for _, tt := range tests {
+		for _, data := range testData {
+			got, err := BlakeReader(tt.size, bytes.NewReader(data), tt.key, tt.pfix)
+			if err != nil {
+				t.Fatalf("%s: %v", tt.name, err)
+			}
+			if want := tt.sum(data); !bytes.Equal(got, want) {
+				t.Errorf("%s/len=%d: got %x, want %x", tt.name, len(data), got, want)
+			}
+		}
+	}
+	if _, err := BlakeReader(65, bytes.NewReader(nil), nil, nil); err == nil {
+		t.Error("size 65: expected error")
+	}
+}
+
+func TestSum160Reader(t *testing.T) {
+	for _, data := range testData {
+		got, err := Sum160Reader(1, bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := Sum160(1, data); !bytes.Equal(got, want) {
+			t.Errorf("len=%d: got %x, want %x", len(data), got, want)
+		}
+	}
+}
+
+// 读取器不在起始位置时，仅计算其后的数据。
+func TestSum160ReaderOffset(t *testing.T) {
+	data := []byte("header|payload data to hash")
+	at := strings.IndexByte(string(data), '|') + 1
+
+	// 已读取部分
+	r := bytes.NewReader(data)
+	if _, err := io.ReadFull(r, make([]byte, at)); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Sum160Reader(1, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := Sum160(1, data[at:]); !bytes.Equal(got, want) {
+		t.Errorf("after read: got %x, want %x", got, want)
+	}
+	// 已定位到中间
+	r = bytes.NewReader(data)
+	if _, err := r.Seek(int64(at), io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ = Sum160Reader(1, r); !bytes.Equal(got, Sum160(1, data[at:])) {
+		t.Errorf("after seek: got %x", got)
+	}
+	// 已到末尾，等同空数据
+	if got, _ = Sum160Reader(1, r); !bytes.Equal(got, Sum160(1, nil)) {
+		t.Errorf("at end: got %x", got)
+	}
+}
+
+func TestSum256Reader(t *testing.T) {
+	for _, data := range testData {
+		got, err := Sum256Reader(1, bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := Sum256(1, data); !bytes.Equal(got, want) {
+			t.Errorf("len=%d: got %x, want %x", len(data), got, want)
+		}
+	}
+}
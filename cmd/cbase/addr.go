@@ -0,0 +1,291 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/cxio/cbase/base58"
+	"github.com/cxio/cbase/paddr"
+)
+
+func init() {
+	commands = append(commands, &command{
+		name:  "addr",
+		brief: "账户地址：哈希、多重签名、编码、解码、校验",
+		run:   runAddr,
+	})
+}
+
+// 地址子命令集。
+var addrSubs = []*subcommand{
+	{"hash", "公钥哈希为公钥地址", addrHash},
+	{"multi", "构造多重签名公钥地址", addrMulti},
+	{"encode", "公钥地址编码为账户地址", addrEncode},
+	{"decode", "账户地址解码为公钥地址", addrDecode},
+	{"check", "校验账户地址并诊断问题", addrCheck},
+}
+
+func runAddr(args []string) error {
+	return dispatch("addr", addrSubs, args)
+}
+
+// 地址信息。
+type addrInfo struct {
+	Address string `json:"address,omitempty"`
+	Prefix  string `json:"prefix,omitempty"`
+	PKAddr  string `json:"pkaddr"`
+	N       int    `json:"n,omitempty"`     // 多重签名最少签名数
+	T       int    `json:"t,omitempty"`     // 多重签名总数
+	Check   int    `json:"check,omitempty"` // 校验方案版本
+}
+
+// 构造地址信息。
+// prefix 为空时不编码账户地址。
+func newAddrInfo(pkh []byte, prefix string) *addrInfo {
+	ai := &addrInfo{PKAddr: hex.EncodeToString(pkh), Prefix: prefix}
+
+	if prefix != "" {
+		ai.Address = paddr.Encode(pkh, prefix)
+	}
+	// 多重签名地址前置 n/T 配比
+	if len(pkh) == paddr.HashSize+2 {
+		ai.N, ai.T = int(pkh[0]), int(pkh[1])
+	}
+	return ai
+}
+
+func (ai *addrInfo) print(w io.Writer) {
+	if ai.Address != "" {
+		fmt.Fprintln(w, "地址:    ", ai.Address)
+	}
+	if ai.Prefix != "" {
+		fmt.Fprintln(w, "前缀:    ", ai.Prefix)
+	}
+	fmt.Fprintln(w, "公钥地址:", ai.PKAddr)
+
+	if ai.T > 0 {
+		fmt.Fprintf(w, "多重签名: %d/%d\n", ai.N, ai.T)
+	}
+	if ai.Check > 0 {
+		fmt.Fprintf(w, "校验方案: v%d\n", ai.Check)
+	}
+}
+
+// cbase addr hash [-prefix p] <公钥>
+func addrHash(args []string) error {
+	fs := newFlags("addr hash", "<公钥（十六进制）>")
+	prefix := fs.String("prefix", "", "标识前缀，设置时同时输出账户地址")
+	asJSON := fs.Bool("json", false, "JSON格式输出")
+
+	if err := parseFlags(fs, args, 1, 1); err != nil {
+		return err
+	}
+	pk, err := decodeHex(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	ai := newAddrInfo(paddr.Hash(pk, nil), *prefix)
+
+	return output(*asJSON, ai, ai.print)
+}
+
+// cbase addr multi -pk i:公钥 ... -pkh i:公钥地址 ...
+func addrMulti(args []string) error {
+	var pks, pkhs listFlag
+
+	fs := newFlags("addr multi", "")
+	fs.Var(&pks, "pk", "已签名公钥，格式 序位:十六进制（可重复）")
+	fs.Var(&pkhs, "pkh", "未签名公钥地址，格式 序位:十六进制（可重复）")
+	prefix := fs.String("prefix", "", "标识前缀，设置时同时输出账户地址")
+	asJSON := fs.Bool("json", false, "JSON格式输出")
+
+	if err := parseFlags(fs, args, 0, 0); err != nil {
+		return err
+	}
+	if len(pks) == 0 {
+		fs.Usage()
+		return errUsage
+	}
+	bpks, err := indexedBytes(pks)
+	if err != nil {
+		return err
+	}
+	bpkhs, err := indexedBytes(pkhs)
+	if err != nil {
+		return err
+	}
+	pkh, err := paddr.MulHash(bpks, bpkhs)
+	if err != nil {
+		return err
+	}
+	ai := newAddrInfo(pkh, *prefix)
+
+	return output(*asJSON, ai, ai.print)
+}
+
+// cbase addr encode -prefix p <公钥地址>
+func addrEncode(args []string) error {
+	fs := newFlags("addr encode", "<公钥地址（十六进制）>")
+	prefix := fs.String("prefix", "", "标识前缀（必需）")
+	asJSON := fs.Bool("json", false, "JSON格式输出")
+
+	if err := parseFlags(fs, args, 1, 1); err != nil {
+		return err
+	}
+	if *prefix == "" {
+		fs.Usage()
+		return errUsage
+	}
+	pkh, err := decodeHex(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	ai := newAddrInfo(pkh, *prefix)
+
+	return output(*asJSON, ai, ai.print)
+}
+
+// cbase addr decode <账户地址>
+func addrDecode(args []string) error {
+	fs := newFlags("addr decode", "<账户地址>")
+	asJSON := fs.Bool("json", false, "JSON格式输出")
+
+	if err := parseFlags(fs, args, 1, 1); err != nil {
+		return err
+	}
+	addr := strings.TrimSpace(fs.Arg(0))
+
+	pkh, prefix, ver, err := paddr.DecodeVer(addr)
+	if err != nil {
+		return fmt.Errorf("%v（%s）", err, strings.Join(diagnose(addr), "；"))
+	}
+	ai := newAddrInfo(pkh, prefix)
+	ai.Address, ai.Check = addr, ver
+
+	return output(*asJSON, ai, ai.print)
+}
+
+// 校验结果。
+type checkResult struct {
+	Address  string   `json:"address"`
+	Valid    bool     `json:"valid"`
+	Error    string   `json:"error,omitempty"`
+	Problems []string `json:"problems,omitempty"`
+	Suggests []string `json:"suggests,omitempty"` // 可能的正确地址
+}
+
+// cbase addr check [-prefix p] <账户地址>...
+// 任一地址无效时退出码为1。
+func addrCheck(args []string) error {
+	fs := newFlags("addr check", "<账户地址>...")
+	prefix := fs.String("prefix", "", "要求的标识前缀，可选")
+	asJSON := fs.Bool("json", false, "JSON格式输出")
+
+	if err := parseFlags(fs, args, 1, -1); err != nil {
+		return err
+	}
+	rs := make([]*checkResult, 0, fs.NArg())
+	bad := false
+
+	for _, addr := range fs.Args() {
+		r := &checkResult{Address: addr, Valid: true}
+		_, pf, err := paddr.Decode(addr)
+
+		switch {
+		case err != nil:
+			r.Valid, r.Error, r.Problems = false, err.Error(), diagnose(addr)
+			r.Suggests = paddr.Suggest(addr, 3, 0)
+		case *prefix != "" && pf != *prefix:
+			r.Valid, r.Error = false, fmt.Sprintf("前缀不符：%q，要求 %q", pf, *prefix)
+		}
+		bad = bad || !r.Valid
+		rs = append(rs, r)
+	}
+	err := output(*asJSON, rs, func(w io.Writer) {
+		for _, r := range rs {
+			if r.Valid {
+				fmt.Fprintf(w, "%s\tOK\n", r.Address)
+				continue
+			}
+			fmt.Fprintf(w, "%s\t无效：%s\n", r.Address, r.Error)
+			for _, p := range r.Problems {
+				fmt.Fprintf(w, "\t- %s\n", p)
+			}
+			for _, s := range r.Suggests {
+				fmt.Fprintf(w, "\t? 是否为 %s\n", s)
+			}
+		}
+	})
+	if err == nil && bad {
+		err = errInvalid
+	}
+	return err
+}
+
+//
+// 私有辅助
+///////////////////////////////////////////////////////////////////////////////
+
+// 解析“序位:十六进制”格式的列表。
+// 返回字节序列首字节为序位。
+func indexedBytes(list []string) ([][]byte, error) {
+	out := make([][]byte, 0, len(list))
+
+	for _, s := range list {
+		i := strings.IndexByte(s, ':')
+		if i < 0 {
+			return nil, fmt.Errorf("缺少序位：%q", s)
+		}
+		n, err := strconv.ParseUint(s[:i], 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("无效的序位：%q", s[:i])
+		}
+		b, err := decodeHex(s[i+1:])
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, append([]byte{byte(n)}, b...))
+	}
+	return out, nil
+}
+
+// 诊断无效地址的具体问题。
+// 逐项检查分隔符、字符合法性和长度，尽量给出可操作的提示。
+func diagnose(addr string) []string {
+	var out []string
+
+	i := strings.IndexByte(addr, paddr.Delimiter)
+	if i < 0 {
+		return append(out, fmt.Sprintf("缺少前缀分隔符 %q", paddr.Delimiter))
+	}
+	if i == 0 {
+		out = append(out, "标识前缀为空")
+	}
+	at := addr[i+1:]
+	if at == "" {
+		return append(out, "文本地址为空")
+	}
+	for j, c := range at {
+		if len(base58.Decode(string(c))) == 0 {
+			out = append(out, fmt.Sprintf("第 %d 个字符 %q 不是Base58字符", i+2+j, c))
+		}
+	}
+	if len(out) > 0 {
+		return out
+	}
+	switch n := len(base58.Decode(at)) - 4; {
+	case n < 1:
+		out = append(out, "数据过短，缺失校验码")
+	case n != paddr.HashSize && n != paddr.HashSize+2:
+		out = append(out, fmt.Sprintf("公钥地址长度 %d 字节，通常应为 %d 或 %d", n, paddr.HashSize, paddr.HashSize+2))
+	default:
+		out = append(out, "校验码不匹配：可能有字符输错或前缀不对")
+	}
+	return out
+}
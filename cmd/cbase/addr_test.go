@@ -0,0 +1,35 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cxio/cbase/paddr"
+)
+
+func TestAddrMultiBadIndex(t *testing.T) {
+	tests := [][]string{
+		{"-pk", "5:0011"},
+		{"-pk", "0:0011", "-pk", "0:0022"},
+		{"-pk", "0:0011", "-pkh", "2:" + hex20},
+		{"-pk", "1:0011", "-pkh", "1:" + hex20},
+		{"-pk", "0:0011", "-pkh", "0:" + hex20},
+	}
+	for _, args := range tests {
+		if err := addrMulti(args); !errors.Is(err, paddr.ErrMSigIndex) {
+			t.Errorf("addr multi %q: got %v, want ErrMSigIndex", args, err)
+		}
+	}
+}
+
+func TestAddrMulti(t *testing.T) {
+	if err := addrMulti([]string{"-json", "-pk", "1:0011", "-pkh", "0:" + hex20}); err != nil {
+		t.Errorf("addr multi: %v", err)
+	}
+}
+
+// 20字节公钥地址（十六进制）。
+const hex20 = "0101010101010101010101010101010101010101"
@@ -0,0 +1,238 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/cxio/cbase/addrbook"
+)
+
+// 默认地址簿文件。
+const defaultBook = "addrbook.json"
+
+func init() {
+	commands = append(commands, &command{
+		name:  "book",
+		brief: "地址簿：添加、移除、列表、搜索、导入、导出、检查",
+		run:   runBook,
+	})
+}
+
+// 地址簿子命令集。
+var bookSubs = []*subcommand{
+	{"add", "添加地址", bookAdd},
+	{"remove", "移除地址", bookRemove},
+	{"list", "列出全部地址", bookList},
+	{"search", "按标签前缀搜索", bookSearch},
+	{"import", "从JSON文件导入", bookImport},
+	{"export", "导出为JSON", bookExport},
+	{"check", "检查JSON文件中的无效或重复条目", bookCheck},
+}
+
+func runBook(args []string) error {
+	return dispatch("book", bookSubs, args)
+}
+
+// 地址簿公共选项。
+type bookFlags struct {
+	file   *string
+	prefix *string
+}
+
+// 添加地址簿公共选项。
+func addBookFlags(fs *flag.FlagSet) *bookFlags {
+	return &bookFlags{
+		file:   fs.String("file", defaultBook, "地址簿文件"),
+		prefix: fs.String("prefix", "", "限定的地址前缀，可选"),
+	}
+}
+
+// 载入地址簿。
+func (bf *bookFlags) load() (*addrbook.Book, error) {
+	return addrbook.Load(*bf.file, *bf.prefix)
+}
+
+// cbase book add [-note n] <标签> <账户地址>
+func bookAdd(args []string) error {
+	fs := newFlags("book add", "<标签> <账户地址>")
+	bf := addBookFlags(fs)
+	note := fs.String("note", "", "备注")
+
+	if err := parseFlags(fs, args, 2, 2); err != nil {
+		return err
+	}
+	b, err := bf.load()
+	if err != nil {
+		return err
+	}
+	if err = b.Add(fs.Arg(0), fs.Arg(1), *note); err != nil {
+		return err
+	}
+	return b.Save(*bf.file)
+}
+
+// cbase book remove <标签>
+func bookRemove(args []string) error {
+	fs := newFlags("book remove", "<标签>")
+	bf := addBookFlags(fs)
+
+	if err := parseFlags(fs, args, 1, 1); err != nil {
+		return err
+	}
+	b, err := bf.load()
+	if err != nil {
+		return err
+	}
+	if err = b.Remove(fs.Arg(0)); err != nil {
+		return err
+	}
+	return b.Save(*bf.file)
+}
+
+// cbase book list [-json]
+func bookList(args []string) error {
+	fs := newFlags("book list", "")
+	bf := addBookFlags(fs)
+	asJSON := fs.Bool("json", false, "JSON格式输出")
+
+	if err := parseFlags(fs, args, 0, 0); err != nil {
+		return err
+	}
+	b, err := bf.load()
+	if err != nil {
+		return err
+	}
+	list := b.List()
+
+	return output(*asJSON, list, func(w io.Writer) { printEntries(w, list) })
+}
+
+// cbase book search [-json] <标签前缀>
+func bookSearch(args []string) error {
+	fs := newFlags("book search", "<标签前缀>")
+	bf := addBookFlags(fs)
+	asJSON := fs.Bool("json", false, "JSON格式输出")
+
+	if err := parseFlags(fs, args, 1, 1); err != nil {
+		return err
+	}
+	b, err := bf.load()
+	if err != nil {
+		return err
+	}
+	list := b.Search(fs.Arg(0))
+
+	return output(*asJSON, list, func(w io.Writer) { printEntries(w, list) })
+}
+
+// cbase book import <文件|->
+// 有效条目被合并，问题条目列出后退出码为1。
+func bookImport(args []string) error {
+	fs := newFlags("book import", "<文件|->")
+	bf := addBookFlags(fs)
+
+	if err := parseFlags(fs, args, 1, 1); err != nil {
+		return err
+	}
+	b, err := bf.load()
+	if err != nil {
+		return err
+	}
+	n := b.Len()
+
+	ps, err := importBook(b, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if err = b.Save(*bf.file); err != nil {
+		return err
+	}
+	fmt.Printf("导入 %d 条，问题 %d 条\n", b.Len()-n, len(ps))
+
+	return printProblems(ps)
+}
+
+// cbase book export [文件]
+// 未指定文件时输出到标准输出。
+func bookExport(args []string) error {
+	fs := newFlags("book export", "[文件]")
+	bf := addBookFlags(fs)
+
+	if err := parseFlags(fs, args, 0, 1); err != nil {
+		return err
+	}
+	b, err := bf.load()
+	if err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return b.Export(os.Stdout)
+	}
+	return b.Save(fs.Arg(0))
+}
+
+// cbase book check [-prefix p] <文件|->
+// 有问题条目时退出码为1。
+func bookCheck(args []string) error {
+	fs := newFlags("book check", "<文件|->")
+	prefix := fs.String("prefix", "", "限定的地址前缀，可选")
+
+	if err := parseFlags(fs, args, 1, 1); err != nil {
+		return err
+	}
+	b := addrbook.New(*prefix)
+
+	ps, err := importBook(b, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("有效 %d 条，问题 %d 条\n", b.Len(), len(ps))
+
+	return printProblems(ps)
+}
+
+//
+// 私有辅助
+///////////////////////////////////////////////////////////////////////////////
+
+// 从文件或标准输入导入。
+func importBook(b *addrbook.Book, name string) ([]*addrbook.Problem, error) {
+	if name == "-" {
+		return b.Import(os.Stdin)
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return b.Import(f)
+}
+
+// 打印条目列表。
+func printEntries(w io.Writer, list []addrbook.Entry) {
+	for _, e := range list {
+		fmt.Fprintf(w, "%s\t%s", e.Label, e.Address)
+		if e.Note != "" {
+			fmt.Fprintf(w, "\t# %s", e.Note)
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// 打印问题条目。
+// 有问题时返回 errInvalid。
+func printProblems(ps []*addrbook.Problem) error {
+	for _, p := range ps {
+		fmt.Fprintf(os.Stderr, "第 %d 条：%v\n", p.Index+1, p)
+	}
+	if len(ps) > 0 {
+		return errInvalid
+	}
+	return nil
+}
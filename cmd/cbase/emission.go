@@ -0,0 +1,155 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cxio/cbase"
+)
+
+func init() {
+	commands = append(commands, &command{
+		name:  "emission",
+		brief: "铸币计划：逐年铸币量及汇总统计",
+		run:   runEmission,
+	})
+}
+
+// 铸币汇总。
+type emissionSummary struct {
+	Base       int64 `json:"base"`        // 初始每块币量（聪）
+	Rate       int64 `json:"rate"`        // 前阶比率（千分值）
+	YearBlocks int64 `json:"year_blocks"` // 每年区块数
+	End        int64 `json:"end"`         // 终止线（聪）
+	Total      int64 `json:"total"`       // 总供应量（聪）
+	Years      int   `json:"years"`       // 铸币年数
+	At         int   `json:"at,omitempty"`
+	AtTotal    int64 `json:"at_total,omitempty"` // 指定年末的累计供应量（聪）
+}
+
+// 铸币报告。
+type emissionReport struct {
+	Summary  *emissionSummary  `json:"summary"`
+	Schedule []cbase.YearAward `json:"schedule"`
+}
+
+// cbase emission -base 币量 -rate 千分值 [-interval 6m] [-end 3] [-format table|csv|json] [-at 年次]
+func runEmission(args []string) error {
+	fs := newFlags("emission", "")
+	base := fs.Float64("base", 0, "初始每块币量（币，必需）")
+	rate := fs.Int64("rate", 0, "前阶比率（千分值，必需），如 900 表示 90%")
+	interval := fs.Duration("interval", 6*time.Minute, "出块间隔")
+	end := fs.Float64("end", float64(cbase.MINTENDLINE)/1e8, "终止线（币），每块币量低于此值后终止")
+	format := fs.String("format", "table", "输出格式：table、csv 或 json")
+	at := fs.Int("at", 0, "汇总中报告指定年末的累计供应量")
+
+	if err := parseFlags(fs, args, 0, 0); err != nil {
+		return err
+	}
+	if *base <= 0 || *rate <= 0 || *interval <= 0 {
+		fs.Usage()
+		return errUsage
+	}
+	blocks := cbase.YearBlocks(*interval)
+	list, err := cbase.Emission(toSatoshi(*base), *rate, blocks, toSatoshi(*end))
+	if err != nil {
+		return err
+	}
+	sum := &emissionSummary{
+		Base:       toSatoshi(*base),
+		Rate:       *rate,
+		YearBlocks: blocks,
+		End:        toSatoshi(*end),
+		Years:      len(list),
+	}
+	if n := len(list); n > 0 {
+		sum.Total = list[n-1].Total
+	}
+	if *at > 0 {
+		sum.At, sum.AtTotal = *at, supplyAt(list, *at)
+	}
+	switch *format {
+	case "json":
+		return output(true, &emissionReport{sum, list}, nil)
+	case "csv":
+		return emissionCSV(os.Stdout, list)
+	case "table":
+		emissionTable(os.Stdout, list, sum)
+		return nil
+	}
+	return fmt.Errorf("未知的输出格式：%q", *format)
+}
+
+// 指定年末的累计供应量。
+// 超出铸币年数时即为总量。
+func supplyAt(list []cbase.YearAward, year int) int64 {
+	if year > len(list) {
+		year = len(list)
+	}
+	if year <= 0 {
+		return 0
+	}
+	return list[year-1].Total
+}
+
+// 输出CSV格式。
+// 金额单位为聪。
+func emissionCSV(w io.Writer, list []cbase.YearAward) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"year", "total", "sum", "base"})
+
+	for _, v := range list {
+		cw.Write([]string{
+			strconv.Itoa(v.Year),
+			strconv.FormatInt(v.Total, 10),
+			strconv.FormatInt(v.Sum, 10),
+			strconv.FormatInt(v.Base, 10),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// 输出表格及汇总。
+// 金额单位为币。
+func emissionTable(w io.Writer, list []cbase.YearAward, sum *emissionSummary) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "年次\t累计\t年计\t币量/块\t")
+
+	for _, v := range list {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t\n", v.Year, formatCoin(v.Total), formatCoin(v.Sum), formatCoin(v.Base))
+	}
+	tw.Flush()
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "每年区块数: %d\n", sum.YearBlocks)
+	fmt.Fprintf(w, "总供应量:   %s\n", formatCoin(sum.Total))
+	fmt.Fprintf(w, "铸币年数:   %d\n", sum.Years)
+
+	if sum.At > 0 {
+		fmt.Fprintf(w, "第 %d 年末:  %s\n", sum.At, formatCoin(sum.AtTotal))
+	}
+}
+
+// 币数转为聪数。
+func toSatoshi(coin float64) int64 {
+	return int64(math.Round(coin * 1e8))
+}
+
+// 聪数格式化为币数文本（保留8位小数）。
+func formatCoin(n int64) string {
+	sign := ""
+	if n < 0 {
+		sign, n = "-", -n
+	}
+	return fmt.Sprintf("%s%d.%08d", sign, n/1e8, n%1e8)
+}
@@ -0,0 +1,150 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/cxio/cbase/base58"
+	"github.com/cxio/cbase/chash"
+)
+
+func init() {
+	commands = append(commands, &command{
+		name:  "hash",
+		brief: "哈希计算：Sum160、Sum256、BLAKE2b 系列",
+		run:   runHash,
+	})
+}
+
+// 哈希计算方式。
+var hashModes = map[string]bool{
+	"sum160":   true,
+	"sum256":   true,
+	"blake160": true,
+	"blake192": true,
+	"blake224": true,
+}
+
+// 哈希结果。
+type hashResult struct {
+	File string `json:"file"`
+	Hash string `json:"hash"`
+}
+
+// cbase hash [-mode m] [-ver n] [-key hex] [-prefix hex] [-base58] [文件...]
+// 无文件参数或文件为 - 时读取标准输入。
+func runHash(args []string) error {
+	fs := newFlags("hash", "[文件...]")
+	mode := fs.String("mode", "sum256", "计算方式：sum160、sum256、blake160、blake192、blake224")
+	ver := fs.Int("ver", 1, "Sum160/Sum256 的版本（目前仅有 1）")
+	key := fs.String("key", "", "BLAKE2b 密钥（十六进制，<=64字节）")
+	pfix := fs.String("prefix", "", "哈希结果的前置字节（十六进制）")
+	b58 := fs.Bool("base58", false, "以Base58输出（默认十六进制）")
+	asJSON := fs.Bool("json", false, "JSON格式输出")
+
+	if err := parseFlags(fs, args, 0, -1); err != nil {
+		return err
+	}
+	if !hashModes[*mode] {
+		return fmt.Errorf("未知的计算方式：%q", *mode)
+	}
+	// chash 目前仅实现版本1
+	if *ver != 1 {
+		return fmt.Errorf("不支持的版本：%d", *ver)
+	}
+	var k, p []byte
+	var err error
+
+	if *key != "" || *pfix != "" {
+		if *mode == "sum160" || *mode == "sum256" {
+			return fmt.Errorf("%s 不支持密钥和前置字节", *mode)
+		}
+		if k, err = decodeHex(*key); err != nil {
+			return err
+		}
+		if p, err = decodeHex(*pfix); err != nil {
+			return err
+		}
+	}
+	files := fs.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+	rs := make([]*hashResult, 0, len(files))
+
+	for _, f := range files {
+		sum, err := hashFile(f, *mode, *ver, k, p)
+		if err != nil {
+			return err
+		}
+		s := hex.EncodeToString(sum)
+		if *b58 {
+			s = base58.Encode(sum)
+		}
+		rs = append(rs, &hashResult{File: f, Hash: s})
+	}
+	return output(*asJSON, rs, func(w io.Writer) {
+		for _, r := range rs {
+			fmt.Fprintf(w, "%s  %s\n", r.Hash, r.File)
+		}
+	})
+}
+
+// 计算文件哈希。
+// 流式读取，Sum160 需两遍读取，标准输入会先暂存到临时文件。
+func hashFile(name, mode string, ver int, key, pfix []byte) ([]byte, error) {
+	f := os.Stdin
+
+	if name != "-" {
+		var err error
+		if f, err = os.Open(name); err != nil {
+			return nil, err
+		}
+		defer f.Close()
+	}
+	switch mode {
+	case "sum160":
+		rs, cleanup, err := seekable(f)
+		if err != nil {
+			return nil, err
+		}
+		defer cleanup()
+		return chash.Sum160Reader(ver, rs)
+	case "sum256":
+		return chash.Sum256Reader(ver, f)
+	case "blake160":
+		return chash.BlakeReader(chash.Size160, f, key, pfix)
+	case "blake192":
+		return chash.BlakeReader(chash.Size192, f, key, pfix)
+	}
+	return chash.BlakeReader(chash.Size224, f, key, pfix)
+}
+
+// 获取可回读的数据源。
+// 普通文件直接使用，管道等不可回读的源暂存到临时文件。
+func seekable(f *os.File) (io.ReadSeeker, func(), error) {
+	if _, err := f.Seek(0, io.SeekCurrent); err == nil {
+		return f, func() {}, nil
+	}
+	tmp, err := os.CreateTemp("", "cbase-hash-*")
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}
+	if _, err = io.Copy(tmp, f); err == nil {
+		_, err = tmp.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	return tmp, cleanup, nil
+}
@@ -0,0 +1,25 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHashVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data")
+	if err := os.WriteFile(path, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, mode := range []string{"sum160", "sum256"} {
+		if err := runHash([]string{"-mode", mode, "-ver", "7", path}); err == nil {
+			t.Errorf("%s -ver 7: expected error", mode)
+		}
+		if err := runHash([]string{"-mode", mode, "-ver", "1", path}); err != nil {
+			t.Errorf("%s -ver 1: %v", mode, err)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+// Cbase 命令行工具，提供账户地址等基础操作。
+//
+// 用法：
+//
+//	cbase <命令> [子命令] [选项] [参数]
+//
+// 输出查询结果的命令（addr、hash、tx decode、book list/search）支持 -json 选项，
+// 以JSON格式输出便于脚本处理；emission 以 -format json 选择JSON输出。
+// 其余命令（book add/remove/import/export/check）没有 -json 选项。
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// 命令。
+type command struct {
+	name  string                    // 名称
+	brief string                    // 简介
+	run   func(args []string) error // 执行入口
+}
+
+// 命令集。
+// 由各命令文件的 init 注册。
+var commands []*command
+
+// 参数错误。
+// 执行时打印用法，退出码为2。
+var errUsage = errors.New("usage")
+
+// 校验失败。
+// 诊断信息已输出，仅设置退出码为1。
+var errInvalid = errors.New("invalid")
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+	name := flag.Arg(0)
+
+	for _, c := range commands {
+		if c.name != name {
+			continue
+		}
+		switch err := c.run(flag.Args()[1:]); err {
+		case nil:
+			return
+		case errUsage:
+			os.Exit(2)
+		case errInvalid:
+			os.Exit(1)
+		default:
+			fatal(err)
+		}
+	}
+	fmt.Fprintf(os.Stderr, "cbase: 未知命令 %q\n", name)
+	usage()
+	os.Exit(2)
+}
+
+// 打印总用法。
+func usage() {
+	fmt.Fprintln(os.Stderr, "用法: cbase <命令> [参数]")
+	fmt.Fprintln(os.Stderr, "\n命令:")
+
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", c.name, c.brief)
+	}
+}
+
+// 输出错误并退出。
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "cbase:", err)
+	os.Exit(1)
+}
+
+//
+// 公共辅助
+///////////////////////////////////////////////////////////////////////////////
+
+// 子命令。
+type subcommand struct {
+	name  string
+	brief string
+	run   func(args []string) error
+}
+
+// 分派子命令。
+// group 为所属命令名，仅用于用法提示。
+func dispatch(group string, subs []*subcommand, args []string) error {
+	if len(args) > 0 {
+		for _, s := range subs {
+			if s.name == args[0] {
+				return s.run(args[1:])
+			}
+		}
+		fmt.Fprintf(os.Stderr, "cbase %s: 未知子命令 %q\n", group, args[0])
+	}
+	fmt.Fprintf(os.Stderr, "用法: cbase %s <子命令> [选项] [参数]\n\n子命令:\n", group)
+
+	for _, s := range subs {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", s.name, s.brief)
+	}
+	return errUsage
+}
+
+// 创建子命令选项集。
+// 出错时不自动退出，由调用者返回 errUsage。
+func newFlags(name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "用法: cbase %s [选项] %s\n", name, args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// 解析选项。
+// 参数数量不在 [min, max] 范围内时打印用法（max<0 表示不限）。
+func parseFlags(fs *flag.FlagSet, args []string, min, max int) error {
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if n := fs.NArg(); n < min || max >= 0 && n > max {
+		fs.Usage()
+		return errUsage
+	}
+	return nil
+}
+
+// 输出结果。
+// asJSON 为真时输出 v 的JSON格式，否则调用 text 输出文本格式。
+func output(asJSON bool, v any, text func(w io.Writer)) error {
+	if !asJSON {
+		text(os.Stdout)
+		return nil
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// 解码十六进制文本。
+// 容许 0x 前缀和首尾空白。
+func decodeHex(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("无效的十六进制数据：%v", err)
+	}
+	return b, nil
+}
+
+// 字符串列表选项（可重复）。
+type listFlag []string
+
+func (l *listFlag) String() string { return strings.Join(*l, ",") }
+
+func (l *listFlag) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
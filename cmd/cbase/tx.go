@@ -0,0 +1,277 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cxio/cbase"
+	"github.com/cxio/cbase/base58"
+	"github.com/cxio/cbase/paddr"
+	"github.com/cxio/cbase/tx"
+)
+
+func init() {
+	commands = append(commands, &command{
+		name:  "tx",
+		brief: "交易：解码与检查",
+		run:   runTx,
+	})
+}
+
+// 交易子命令集。
+var txSubs = []*subcommand{
+	{"decode", "解码交易并检查问题", txDecode},
+}
+
+func runTx(args []string) error {
+	return dispatch("tx", txSubs, args)
+}
+
+// 交易解码视图。
+type txView struct {
+	TxID     string     `json:"txid"`
+	Size     int        `json:"size"`
+	Header   headerView `json:"header"`
+	HashBody string     `json:"hash_body"` // 重新计算的交易体哈希
+	Vins     []vinView  `json:"vins"`
+	Vouts    []voutView `json:"vouts"`
+	Total    int64      `json:"total"` // 币金输出合计（聪）
+	Problems []string   `json:"problems,omitempty"`
+}
+
+type headerView struct {
+	Version   int32  `json:"version"`
+	Timestamp int64  `json:"timestamp"`
+	Time      string `json:"time"`
+	BlockLink string `json:"block_link"`
+	Minter    string `json:"minter"`
+	Scale     uint8  `json:"scale"`
+	Staker    string `json:"staker,omitempty"`
+	Lock      uint32 `json:"lock_height,omitempty"`
+	Expiry    uint32 `json:"expiry,omitempty"`
+	HashBody  string `json:"hash_body"`
+}
+
+type vinView struct {
+	KeyID  string `json:"keyid"`
+	Height int    `json:"height"`
+	Tx     int    `json:"tx"`
+	Index  int    `json:"index"`
+}
+
+type voutView struct {
+	Type     string `json:"type"`
+	Receiver string `json:"receiver,omitempty"`
+	Amount   int64  `json:"amount,omitempty"`
+	Script   string `json:"script,omitempty"`
+
+	Creator     string `json:"creator,omitempty"`
+	Description string `json:"description,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Content     string `json:"content,omitempty"`
+	Attachment  string `json:"attachment,omitempty"`
+}
+
+// cbase tx decode [-format auto|hex|base58|bin] [-prefix p] [-json] <数据|->
+// 参数为 - 时从标准输入读取，-file 指定时从文件读取。
+func txDecode(args []string) error {
+	fs := newFlags("tx decode", "[数据|-]")
+	format := fs.String("format", "auto", "输入格式：auto、hex、base58 或 bin")
+	file := fs.String("file", "", "从文件读取")
+	prefix := fs.String("prefix", "", "标识前缀，设置时公钥地址显示为账户地址")
+	asJSON := fs.Bool("json", false, "JSON格式输出")
+
+	if err := parseFlags(fs, args, 0, 1); err != nil {
+		return err
+	}
+	var raw []byte
+	var err error
+
+	switch {
+	case *file != "":
+		raw, err = os.ReadFile(*file)
+	case fs.NArg() == 0 || fs.Arg(0) == "-":
+		raw, err = io.ReadAll(os.Stdin)
+	default:
+		raw = []byte(fs.Arg(0))
+	}
+	if err != nil {
+		return err
+	}
+	data, err := decodeInput(raw, *format)
+	if err != nil {
+		return err
+	}
+	t, err := tx.Decode(data)
+	if err != nil {
+		return fmt.Errorf("交易解码失败：%v", err)
+	}
+	v := newTxView(t, len(data), *prefix)
+
+	if err = output(*asJSON, v, v.print); err == nil && len(v.Problems) > 0 {
+		err = errInvalid
+	}
+	return err
+}
+
+// 解码输入数据。
+// auto 格式依次尝试十六进制、Base58，都不符合时视为二进制。
+func decodeInput(raw []byte, format string) ([]byte, error) {
+	text := strings.TrimSpace(string(raw))
+
+	switch format {
+	case "hex":
+		return decodeHex(text)
+	case "base58":
+		b := base58.Decode(text)
+		if len(b) == 0 {
+			return nil, fmt.Errorf("无效的Base58数据")
+		}
+		return b, nil
+	case "bin":
+		return raw, nil
+	case "auto":
+		if b, err := decodeHex(text); err == nil && len(b) > 0 {
+			return b, nil
+		}
+		if b := base58.Decode(text); len(b) > 0 {
+			return b, nil
+		}
+		return raw, nil
+	}
+	return nil, fmt.Errorf("未知的输入格式：%q", format)
+}
+
+// 构造交易视图。
+func newTxView(t *tx.Tx, size int, prefix string) *txView {
+	h := &t.Header
+	hb := t.Body.Hash()
+
+	v := &txView{
+		TxID:     t.ID().String(),
+		Size:     size,
+		HashBody: hex.EncodeToString(hb),
+		Header: headerView{
+			Version:   h.Version,
+			Timestamp: h.Timestamp,
+			Time:      time.UnixMilli(h.Timestamp).UTC().Format(time.RFC3339Nano),
+			BlockLink: hex.EncodeToString(h.BlockLink[:]),
+			Minter:    showPKAddr(h.Minter, prefix),
+			Scale:     h.Scale,
+			Staker:    showPKAddr(h.Staker, prefix),
+			Lock:      h.LockHeight,
+			Expiry:    h.Expiry,
+			HashBody:  hex.EncodeToString(h.HashBody),
+		},
+	}
+	for _, in := range t.Body.Vins() {
+		hi, n, i := cbase.SplitKeyID(in[:])
+		v.Vins = append(v.Vins, vinView{KeyID: hex.EncodeToString(in[:]), Height: hi, Tx: n, Index: i})
+	}
+	for _, out := range t.Body.Vouts() {
+		vv := voutView{Receiver: showPKAddr(out.Receiver(), prefix)}
+
+		switch {
+		case out.Coin() != nil:
+			c := out.Coin()
+			vv.Type, vv.Amount, vv.Script = "coin", c.Amount, hex.EncodeToString(c.Script)
+			v.Total += c.Amount
+		case out.Credit() != nil:
+			c := out.Credit()
+			vv.Type, vv.Script = "credit", hex.EncodeToString(c.Script)
+			vv.Creator, vv.Description = hex.EncodeToString(c.Creator), string(c.Description)
+			vv.Attachment = hex.EncodeToString(c.Attachment)
+		case out.Evidence() != nil:
+			e := out.Evidence()
+			vv.Type, vv.Script = "evidence", hex.EncodeToString(e.Script)
+			vv.Title, vv.Content = string(e.Title), string(e.Content)
+			vv.Attachment = hex.EncodeToString(e.Attachment)
+		}
+		v.Vouts = append(v.Vouts, vv)
+	}
+	for _, err := range t.Check() {
+		v.Problems = append(v.Problems, err.Error())
+	}
+	return v
+}
+
+// 文本格式输出。
+// 有问题的项以 ! 标记。
+func (v *txView) print(w io.Writer) {
+	h := &v.Header
+	mark := func(bad bool) string {
+		if bad {
+			return "!"
+		}
+		return " "
+	}
+	fmt.Fprintf(w, "交易ID:   %s\n", v.TxID)
+	fmt.Fprintf(w, "大小:     %d 字节\n\n", v.Size)
+	fmt.Fprintf(w, "[交易头]\n")
+	fmt.Fprintf(w, "  版本:     %d\n", h.Version)
+	fmt.Fprintf(w, "  时间戳:   %d (%s)\n", h.Timestamp, h.Time)
+	fmt.Fprintf(w, "  主链绑定: %s\n", h.BlockLink)
+	fmt.Fprintf(w, "  铸造地址: %s\n", h.Minter)
+	fmt.Fprintf(w, "  收益分成: %d/100\n", h.Scale)
+
+	if h.Staker != "" {
+		fmt.Fprintf(w, "  收益地址: %s\n", h.Staker)
+	}
+	if h.Lock != 0 {
+		fmt.Fprintf(w, "  锁定高度: %d\n", h.Lock)
+	}
+	if h.Expiry != 0 {
+		fmt.Fprintf(w, "  失效高度: %d\n", h.Expiry)
+	}
+	fmt.Fprintf(w, "%s 体哈希:   %s\n", mark(h.HashBody != v.HashBody), h.HashBody)
+	if h.HashBody != v.HashBody {
+		fmt.Fprintf(w, "  （计算值: %s）\n", v.HashBody)
+	}
+	fmt.Fprintf(w, "\n[输入] %d 项\n", len(v.Vins))
+
+	for i, in := range v.Vins {
+		fmt.Fprintf(w, "  #%d %s (高度 %d，交易 %d，脚本 %d)\n", i, in.KeyID, in.Height, in.Tx, in.Index)
+	}
+	fmt.Fprintf(w, "\n[输出] %d 项\n", len(v.Vouts))
+
+	for i, out := range v.Vouts {
+		switch out.Type {
+		case "coin":
+			fmt.Fprintf(w, "  #%d 币金 %s -> %s\n", i, formatCoin(out.Amount), out.Receiver)
+		case "credit":
+			fmt.Fprintf(w, "  #%d 凭信 %q -> %s\n", i, out.Description, out.Receiver)
+		case "evidence":
+			fmt.Fprintf(w, "  #%d 证据 %q\n", i, out.Title)
+		}
+		if out.Script != "" {
+			fmt.Fprintf(w, "     脚本: %s\n", out.Script)
+		}
+	}
+	fmt.Fprintf(w, "\n币金合计: %s\n", formatCoin(v.Total))
+
+	if len(v.Problems) > 0 {
+		fmt.Fprintf(w, "\n[问题] %d 项\n", len(v.Problems))
+		for _, p := range v.Problems {
+			fmt.Fprintf(w, "  ! %s\n", p)
+		}
+	}
+}
+
+// 显示公钥地址。
+// 设置了前缀时编码为账户地址，否则为十六进制。
+func showPKAddr(pkh []byte, prefix string) string {
+	switch {
+	case len(pkh) == 0:
+		return ""
+	case prefix != "":
+		return paddr.Encode(pkh, prefix)
+	}
+	return hex.EncodeToString(pkh)
+}
@@ -10,7 +10,9 @@ import (
 	"fmt"
 	"math"
 	"regexp"
+	"time"
 
+	"github.com/cxio/cbase/cerror"
 	"github.com/cxio/script/instor"
 )
 
@@ -34,6 +36,21 @@ func KeyID(h, n, i int) []byte {
 	return buf.Bytes()
 }
 
+// 解析脚本ID。
+// 为 KeyID 的逆操作，返回理想块高度、交易序位和脚本序位。
+// 如果 id 长度不足（4+4+2字节），返回全零值。
+// 注：
+// 存放在 KeyIDSize 长度的空间中时，末尾多余字节为零。
+func SplitKeyID(id []byte) (h, n, i int) {
+	if len(id) < 10 {
+		return
+	}
+	h = int(binary.BigEndian.Uint32(id[0:4]))
+	n = int(binary.BigEndian.Uint32(id[4:8]))
+	i = int(binary.BigEndian.Uint16(id[8:10]))
+	return
+}
+
 /*
  * 基本工具
  ******************************************************************************
@@ -58,35 +75,107 @@ func ToAnys[T Itemer](data []T) []any {
 	return buf
 }
 
+// 文本类型约束。
+// 正则匹配的目标类型。
+type Texter interface {
+	string | []byte
+}
+
+// 匹配目标类型错误。
+var ErrMatchTarget = cerror.New(101, "匹配目标须为字符串或字节序列")
+
 // 查找首个正则匹配。
 // 返回一个切片，其中首个成员为完整匹配，后续为可能有的子匹配。
-// 目标 target 支持字符串或字节序列。
-func Match(target any, re *regexp.Regexp) []any {
-	if x, ok := target.(string); ok {
-		return ToAnys(re.FindStringSubmatch(x))
+// 目标 target 支持字符串或字节序列，其它类型返回 ErrMatchTarget。
+func Match(target any, re *regexp.Regexp) ([]any, error) {
+	switch x := target.(type) {
+	case string:
+		return ToAnys(MatchOf(x, re)), nil
+	case []byte:
+		return ToAnys(MatchOf(x, re)), nil
 	}
-	return ToAnys(re.FindSubmatch(target.([]byte)))
+	return nil, ErrMatchTarget.With(fmt.Sprintf("%T", target))
 }
 
 // 查找全部匹配。
 // 仅查找完整匹配的结果，子匹配会被简单忽略。
-// 目标 target 支持字符串或字节序列。
-func MatchAll(target any, re *regexp.Regexp) []any {
-	if x, ok := target.(string); ok {
-		return ToAnys(re.FindAllString(x, -1))
+// 目标 target 支持字符串或字节序列，其它类型返回 ErrMatchTarget。
+func MatchAll(target any, re *regexp.Regexp) ([]any, error) {
+	switch x := target.(type) {
+	case string:
+		return ToAnys(MatchAllOf(x, re)), nil
+	case []byte:
+		return ToAnys(MatchAllOf(x, re)), nil
 	}
-	return ToAnys(re.FindAll(target.([]byte), -1))
+	return nil, ErrMatchTarget.With(fmt.Sprintf("%T", target))
 }
 
 // 查找所有的匹配。
 // 会检查每一个匹配的子匹配，返回包含每组匹配的子匹配的一个二维切片。
 // 如果子匹配式本身就不存在，每组匹配依然是一个切片结果（即整体依然为二维）。
-// 目标 target 支持字符串或字节序列。
-func MatchEvery(target any, re *regexp.Regexp) []any {
-	if x, ok := target.(string); ok {
-		return ToAnys(re.FindAllStringSubmatch(x, -1))
+// 目标 target 支持字符串或字节序列，其它类型返回 ErrMatchTarget。
+func MatchEvery(target any, re *regexp.Regexp) ([]any, error) {
+	switch x := target.(type) {
+	case string:
+		return ToAnys(MatchEveryOf(x, re)), nil
+	case []byte:
+		return ToAnys(MatchEveryOf(x, re)), nil
+	}
+	return nil, ErrMatchTarget.With(fmt.Sprintf("%T", target))
+}
+
+// 查找首个正则匹配（泛型版）。
+// 首个成员为完整匹配，后续为子匹配，无匹配时返回nil。
+func MatchOf[T Texter](target T, re *regexp.Regexp) []T {
+	switch x := any(target).(type) {
+	case string:
+		return any(re.FindStringSubmatch(x)).([]T)
+	case []byte:
+		return any(re.FindSubmatch(x)).([]T)
+	}
+	return nil
+}
+
+// 查找全部匹配（泛型版）。
+// 仅返回完整匹配，无匹配时返回nil。
+func MatchAllOf[T Texter](target T, re *regexp.Regexp) []T {
+	switch x := any(target).(type) {
+	case string:
+		return any(re.FindAllString(x, -1)).([]T)
+	case []byte:
+		return any(re.FindAll(x, -1)).([]T)
+	}
+	return nil
+}
+
+// 查找所有的匹配（泛型版）。
+// 每组匹配为一个切片，首个成员为完整匹配，后续为子匹配。
+func MatchEveryOf[T Texter](target T, re *regexp.Regexp) [][]T {
+	switch x := any(target).(type) {
+	case string:
+		return any(re.FindAllStringSubmatch(x, -1)).([][]T)
+	case []byte:
+		return any(re.FindAllSubmatch(x, -1)).([][]T)
+	}
+	return nil
+}
+
+// 查找首个匹配的命名子匹配。
+// 返回以子匹配名为键的集合，未命名的子匹配被忽略。
+// 无匹配时返回nil，未参与匹配的命名子匹配值为零值。
+func MatchNamed[T Texter](target T, re *regexp.Regexp) map[string]T {
+	sub := MatchOf(target, re)
+	if sub == nil {
+		return nil
+	}
+	out := make(map[string]T)
+
+	for i, name := range re.SubexpNames() {
+		if i > 0 && name != "" {
+			out[name] = sub[i]
+		}
 	}
-	return ToAnys(re.FindAllSubmatch(target.([]byte), -1))
+	return out
 }
 
 //
@@ -101,6 +190,53 @@ const SY6BLOCKS = 87661
 // 即每块低于 3币 后终止。
 const MINTENDLINE = 3e8
 
+// 恒星年时长。
+// 约 365.25636 日。
+const SiderealYear = 365*24*time.Hour + 6*time.Hour + 9*time.Minute + 9760*time.Millisecond
+
+// 年度铸币。
+type YearAward struct {
+	Year  int   `json:"year"`  // 年次
+	Total int64 `json:"total"` // 累计总量（聪）
+	Sum   int64 `json:"sum"`   // 本年总量（聪）
+	Base  int64 `json:"base"`  // 本年每块币量（聪）
+}
+
+// 铸币参数错误。
+var ErrEmission = cerror.New(102, "铸币参数设置错误")
+
+// 按出块间隔计算每年的区块数量。
+// 以恒星年计，6分钟间隔即为 SY6BLOCKS。
+func YearBlocks(interval time.Duration) int64 {
+	return int64(SiderealYear / interval)
+}
+
+// 铸币计划计算。
+// base 初始每块币量（单位：聪）。
+// rate 前阶比率（千分值），如 900 表示 90%。
+// blocks 每年的区块数量。
+// end 原始铸币终止线（单位：聪），每块币量低于此值后终止。
+// 返回：逐年铸币清单。
+func Emission(base, rate, blocks, end int64) ([]YearAward, error) {
+	if rate < 0 || rate >= 1000 || blocks <= 0 || end <= 0 {
+		return nil, ErrEmission
+	}
+	var sum int64
+	var out []YearAward
+
+	for y := 1; base >= end; y++ {
+		if base > math.MaxInt64/blocks || sum > math.MaxInt64-base*blocks {
+			return nil, ErrEmission
+		}
+		ysum := base * blocks
+		sum += ysum
+		out = append(out, YearAward{Year: y, Total: sum, Sum: ysum, Base: base})
+
+		base = base * rate / 1000
+	}
+	return out, nil
+}
+
 // 奖励总量计算&打印。
 // base 初始每块币量（单位：币）。
 // rate 前阶比率（千分值），如 900 表示 90%。
@@ -109,25 +245,18 @@ func AwardTotal(base, rate int64) int64 {
 	if rate >= 1000 {
 		panic("比率设置错误")
 	}
-	var sum int64
-	y := 0
 	// 1币 = 1亿聪
-	base *= 1e8
-
+	list, err := Emission(base*1e8, rate, SY6BLOCKS, MINTENDLINE)
+	if err != nil {
+		panic(err)
+	}
 	fmt.Println("年次\t累计\t\t\t（年计）\t\t币量/块")
 	fmt.Println("----------------------------------------------------------------------")
 
-	for {
-		// 低于 3币/块 时止
-		if base < MINTENDLINE {
-			break
-		}
-		ysum := base * SY6BLOCKS
-		sum += ysum
-		y++
-		fmt.Printf("%d\t%d \t(%d)\t%d\n", y, sum, ysum, base)
-
-		base = base * rate / 1000
+	var sum int64
+	for _, v := range list {
+		fmt.Printf("%d\t%d \t(%d)\t%d\n", v.Year, v.Total, v.Sum, v.Base)
+		sum = v.Total
 	}
 	return sum
 }
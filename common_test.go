@@ -0,0 +1,193 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package cbase_test
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cxio/cbase"
+)
+
+var reKV = regexp.MustCompile(`(?P<key>\w+)=(?P<val>\w*)`)
+
+func TestMatchOf(t *testing.T) {
+	if got, want := cbase.MatchOf("a=1 b=2", reKV), []string{"a=1", "a", "1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("MatchOf string: got %q, want %q", got, want)
+	}
+	if got, want := cbase.MatchAllOf([]byte("a=1 b=2"), reKV), [][]byte{[]byte("a=1"), []byte("b=2")}; !reflect.DeepEqual(got, want) {
+		t.Errorf("MatchAllOf bytes: got %q, want %q", got, want)
+	}
+	want := [][]string{{"a=1", "a", "1"}, {"b=", "b", ""}}
+	if got := cbase.MatchEveryOf("a=1 b=", reKV); !reflect.DeepEqual(got, want) {
+		t.Errorf("MatchEveryOf: got %q, want %q", got, want)
+	}
+	if got := cbase.MatchOf("none", reKV); got != nil {
+		t.Errorf("MatchOf no match: got %q, want nil", got)
+	}
+}
+
+func TestMatchNamed(t *testing.T) {
+	got := cbase.MatchNamed("x=42", reKV)
+	if got["key"] != "x" || got["val"] != "42" || len(got) != 2 {
+		t.Errorf("MatchNamed: got %v", got)
+	}
+	if got := cbase.MatchNamed([]byte("--"), reKV); got != nil {
+		t.Errorf("MatchNamed no match: got %v, want nil", got)
+	}
+}
+
+func TestMatchTarget(t *testing.T) {
+	if _, err := cbase.Match(42, reKV); !errors.Is(err, cbase.ErrMatchTarget) {
+		t.Errorf("Match int: got %v, want ErrMatchTarget", err)
+	}
+	got, err := cbase.MatchAll("a=1 b=2", reKV)
+	if err != nil || len(got) != 2 || got[1].(string) != "b=2" {
+		t.Errorf("MatchAll: got %v, %v", got, err)
+	}
+}
+
+func TestMatchReader(t *testing.T) {
+	input := "a=1 b=2\r\nnone\nc=3"
+	var got []string
+
+	err := cbase.MatchReader(strings.NewReader(input), reKV, func(line int, sub [][]byte) bool {
+		got = append(got, fmt.Sprintf("%d:%s", line, sub[1]))
+		return true
+	})
+	if want := []string{"1:a", "1:b", "3:c"}; err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("MatchReader: got %v, %v, want %v", got, err, want)
+	}
+	got = nil
+
+	err = cbase.MatchRuneReader(strings.NewReader(input), reKV, func(line int, sub []string) bool {
+		got = append(got, fmt.Sprintf("%d:%s", line, sub[2]))
+		return len(got) < 2
+	})
+	if want := []string{"1:1", "1:2"}; err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("MatchRuneReader: got %v, %v, want %v", got, err, want)
+	}
+}
+
+func TestRegexpCache(t *testing.T) {
+	a, err := cbase.Regexp(`\d+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := cbase.MustRegexp(`\d+`); a != b {
+		t.Error("Regexp: cached instance not reused")
+	}
+	if _, err := cbase.Regexp(`(`); err == nil {
+		t.Error("Regexp: want error for invalid pattern")
+	}
+	if got, err := cbase.MatchAllText("a1b22", `\d+`); err != nil || len(got) != 2 || got[1] != "22" {
+		t.Errorf("MatchAllText: got %q, %v", got, err)
+	}
+}
+
+func TestYearBlocks(t *testing.T) {
+	tests := []struct {
+		interval time.Duration
+		want     int64
+	}{
+		{6 * time.Minute, cbase.SY6BLOCKS},
+		{time.Minute, 525969},
+		{10 * time.Minute, 52596},
+	}
+	for _, tt := range tests {
+		if got := cbase.YearBlocks(tt.interval); got != tt.want {
+			t.Errorf("YearBlocks(%v) = %d, want %d", tt.interval, got, tt.want)
+		}
+	}
+}
+
+func TestEmission(t *testing.T) {
+	list, err := cbase.Emission(40e8, 900, cbase.SY6BLOCKS, cbase.MINTENDLINE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 25 {
+		t.Fatalf("got %d years, want 25", len(list))
+	}
+	want := []cbase.YearAward{
+		{Year: 1, Total: 350644000000000, Sum: 350644000000000, Base: 4000000000},
+		{Year: 2, Total: 666223600000000, Sum: 315579600000000, Base: 3600000000},
+		{Year: 3, Total: 950245240000000, Sum: 284021640000000, Base: 3240000000},
+	}
+	if !reflect.DeepEqual(list[:3], want) {
+		t.Errorf("first years: got %+v", list[:3])
+	}
+	last := cbase.YearAward{Year: 25, Total: 3254713375616542, Sum: 27969624376309, Base: 319065769}
+	if list[24] != last {
+		t.Errorf("last year: got %+v, want %+v", list[24], last)
+	}
+	// 逐年累计一致
+	var sum int64
+	for i, v := range list {
+		sum += v.Sum
+		if v.Year != i+1 || v.Total != sum || v.Sum != v.Base*cbase.SY6BLOCKS || v.Base < cbase.MINTENDLINE {
+			t.Errorf("year %d: inconsistent %+v", i+1, v)
+		}
+	}
+	// 初始即低于终止线
+	if list, err = cbase.Emission(1e8, 900, cbase.SY6BLOCKS, cbase.MINTENDLINE); err != nil || len(list) != 0 {
+		t.Errorf("below end: got %v, %v", list, err)
+	}
+}
+
+func TestEmissionError(t *testing.T) {
+	tests := []struct {
+		name                    string
+		base, rate, blocks, end int64
+	}{
+		{"negative rate", 40e8, -1, cbase.SY6BLOCKS, cbase.MINTENDLINE},
+		{"rate 1000", 40e8, 1000, cbase.SY6BLOCKS, cbase.MINTENDLINE},
+		{"zero blocks", 40e8, 900, 0, cbase.MINTENDLINE},
+		{"zero end", 40e8, 900, cbase.SY6BLOCKS, 0},
+		{"negative end", 40e8, 900, cbase.SY6BLOCKS, -1},
+		{"year overflow", math.MaxInt64 / 2, 900, 3, 1},
+		{"total overflow", math.MaxInt64 / cbase.SY6BLOCKS, 999, cbase.SY6BLOCKS, 1},
+	}
+	for _, tt := range tests {
+		if _, err := cbase.Emission(tt.base, tt.rate, tt.blocks, tt.end); !errors.Is(err, cbase.ErrEmission) {
+			t.Errorf("%s: got %v, want ErrEmission", tt.name, err)
+		}
+	}
+}
+
+// 改用 Emission 之前的 AwardTotal 算法（不含打印）。
+func legacyAwardTotal(base, rate int64) int64 {
+	var sum int64
+	base *= 1e8
+
+	for base >= cbase.MINTENDLINE {
+		sum += base * cbase.SY6BLOCKS
+		base = base * rate / 1000
+	}
+	return sum
+}
+
+func TestAwardTotal(t *testing.T) {
+	tests := []struct {
+		base, rate int64
+		want       int64
+	}{
+		{40, 900, 3254713375616542},
+		{50, 800, 2071044638884704},
+		{10, 500, 131491500000000},
+		{2, 900, 0},
+	}
+	for _, tt := range tests {
+		got := cbase.AwardTotal(tt.base, tt.rate)
+		if got != tt.want || got != legacyAwardTotal(tt.base, tt.rate) {
+			t.Errorf("AwardTotal(%d, %d) = %d, want %d", tt.base, tt.rate, got, tt.want)
+		}
+	}
+}
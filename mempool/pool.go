@@ -0,0 +1,280 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+// Package mempool 交易池：暂存待打包的交易。
+//
+// 尚未到达锁定高度的交易被持有，到期后自动成为可打包交易；
+// 超过失效高度的交易被丢弃。
+package mempool
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cxio/cbase/block"
+	"github.com/cxio/cbase/cerror"
+	"github.com/cxio/cbase/tx"
+)
+
+// 默认的持有范围（区块数）。
+const DefaultHoldAhead = 1000
+
+var (
+	// 交易已存在。
+	ErrExists = cerror.New(10001, "交易已在交易池中")
+
+	// 输入冲突。
+	ErrConflict = cerror.New(10002, "交易与池中交易花费了相同的输入")
+
+	// 锁定过久。
+	ErrTooFar = cerror.New(10003, "交易锁定高度超出持有范围")
+)
+
+// Options 交易池配置。
+type Options struct {
+	HoldAhead int              // 持有范围：锁定高度最多超出下一区块高度的数量，0 为默认值
+	Now       func() time.Time // 时间源，nil 为 time.Now
+}
+
+// 池中条目。
+type entry struct {
+	tx  *tx.Tx
+	seq uint64 // 加入序号
+}
+
+// Pool 交易池。
+// 可安全地并发使用。
+type Pool struct {
+	mu     sync.Mutex
+	opt    Options
+	height int                // 当前链端高度
+	txs    map[tx.TxID]*entry // 全部交易
+	spends map[tx.Vin]tx.TxID // 输入到花费交易
+	seq    uint64             // 加入计数
+}
+
+// New 创建交易池。
+// height 为当前链端高度，新交易按下一区块高度检查。
+func New(height int, opt Options) *Pool {
+	if opt.HoldAhead <= 0 {
+		opt.HoldAhead = DefaultHoldAhead
+	}
+	if opt.Now == nil {
+		opt.Now = time.Now
+	}
+	return &Pool{
+		opt:    opt,
+		height: height,
+		txs:    make(map[tx.TxID]*entry),
+		spends: make(map[tx.Vin]tx.TxID),
+	}
+}
+
+// Height 当前链端高度。
+func (p *Pool) Height() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.height
+}
+
+// Len 交易总数（含持有的）。
+func (p *Pool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return len(p.txs)
+}
+
+// Add 加入交易。
+// 交易须结构合法、未失效、时间戳未超前且不与池中交易冲突。
+// 尚未到达锁定高度的交易被持有，锁定高度超出持有范围时拒绝。
+func (p *Pool) Add(t *tx.Tx) error {
+	if errs := t.Check(); errs != nil {
+		return errs[0]
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.add(t)
+}
+
+// Remove 移除交易。
+func (p *Pool) Remove(id tx.TxID) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.txs[id]; !ok {
+		return false
+	}
+	p.remove(id)
+	return true
+}
+
+// Get 获取交易。
+func (p *Pool) Get(id tx.TxID) *tx.Tx {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if e, ok := p.txs[id]; ok {
+		return e.tx
+	}
+	return nil
+}
+
+// Ready 可被下一区块打包的交易。
+// 即在下一区块高度和当前时间下通过 CheckFinal 的交易。
+// 按加入顺序排列。
+func (p *Pool) Ready() []*tx.Tx {
+	now := p.opt.Now()
+
+	return p.list(func(t *tx.Tx, next int) bool {
+		return t.CheckFinal(next, now) == nil
+	})
+}
+
+// Held 尚在锁定中而被持有的交易。
+// 按加入顺序排列。
+func (p *Pool) Held() []*tx.Tx {
+	return p.list(func(t *tx.Tx, next int) bool {
+		return t.Header.Locked(next)
+	})
+}
+
+// Connect 连接新区块。
+// 移除区块已打包的交易和与之冲突的交易，推进高度，并丢弃在新高度下失效的交易。
+// 返回被丢弃的交易（不含已打包的）。
+func (p *Pool) Connect(b *block.Block) []*tx.Tx {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var dropped []*tx.Tx
+
+	for _, t := range b.Txs {
+		id := t.ID()
+		if _, ok := p.txs[id]; ok {
+			p.remove(id)
+			continue
+		}
+		for _, in := range t.Body.Vins() {
+			if other, ok := p.spends[in]; ok {
+				dropped = append(dropped, p.txs[other].tx)
+				p.remove(other)
+			}
+		}
+	}
+	p.height = b.Height
+
+	return append(dropped, p.expire()...)
+}
+
+// Disconnect 断开链端区块。
+// 高度回退，区块中的交易尽量重新加入（无法加入的被忽略）。
+// 回退与重新加入在同一次加锁中完成。
+func (p *Pool) Disconnect(b *block.Block) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.height = b.Height - 1
+
+	for _, t := range b.Txs {
+		// 区块中的交易已通过结构检查；
+		// 与池中交易冲突或在回退后的高度下失效的，有意忽略其错误
+		p.add(t)
+	}
+}
+
+//
+// 私有辅助
+///////////////////////////////////////////////////////////////////////////////
+
+// 加入交易（已加锁）。
+// 锁定中的交易按解锁高度检查失效和时间戳，
+// 以免持有永远无法打包的交易。
+func (p *Pool) add(t *tx.Tx) error {
+	id := t.ID()
+	if _, ok := p.txs[id]; ok {
+		return ErrExists.With(id)
+	}
+	for _, in := range t.Body.Vins() {
+		if other, ok := p.spends[in]; ok {
+			return ErrConflict.With(other)
+		}
+	}
+	at := p.height + 1
+
+	if t.Header.Locked(at) {
+		if int64(t.Header.LockHeight) > int64(at+p.opt.HoldAhead) {
+			return ErrTooFar.With(t.Header.LockHeight)
+		}
+		at = int(t.Header.LockHeight)
+	}
+	if err := t.CheckFinal(at, p.opt.Now()); err != nil {
+		return err
+	}
+	p.insert(id, t)
+
+	return nil
+}
+
+// 插入交易（已加锁）。
+func (p *Pool) insert(id tx.TxID, t *tx.Tx) {
+	p.seq++
+	p.txs[id] = &entry{tx: t, seq: p.seq}
+
+	for _, in := range t.Body.Vins() {
+		p.spends[in] = id
+	}
+}
+
+// 移除交易（已加锁）。
+func (p *Pool) remove(id tx.TxID) {
+	for _, in := range p.txs[id].tx.Body.Vins() {
+		delete(p.spends, in)
+	}
+	delete(p.txs, id)
+}
+
+// 丢弃在下一区块高度失效的交易（已加锁）。
+func (p *Pool) expire() []*tx.Tx {
+	var out []*tx.Tx
+	next := p.height + 1
+
+	for _, e := range p.sorted() {
+		if e.tx.Header.Expired(next) {
+			out = append(out, e.tx)
+			p.remove(e.tx.ID())
+		}
+	}
+	return out
+}
+
+// 列出满足条件的交易。
+// next 为下一区块高度。
+func (p *Pool) list(ok func(t *tx.Tx, next int) bool) []*tx.Tx {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var out []*tx.Tx
+	next := p.height + 1
+
+	for _, e := range p.sorted() {
+		if ok(e.tx, next) {
+			out = append(out, e.tx)
+		}
+	}
+	return out
+}
+
+// 按加入顺序排列的条目（已加锁）。
+func (p *Pool) sorted() []*entry {
+	out := make([]*entry, 0, len(p.txs))
+
+	for _, e := range p.txs {
+		out = append(out, e)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].seq < out[j].seq })
+
+	return out
+}
@@ -0,0 +1,164 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package mempool
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cxio/cbase"
+	"github.com/cxio/cbase/block"
+	"github.com/cxio/cbase/tx"
+)
+
+// 测试时间。
+var testNow = time.UnixMilli(1660000000000)
+
+// 构造测试交易。
+// 花费 (h, 0, in) 输出，锁定在 lock，失效于 expiry。
+func testTx(h, in int, lock, expiry uint32) *tx.Tx {
+	return testTxAt(h, in, lock, expiry, testNow)
+}
+
+// 构造指定时间戳的测试交易。
+func testTxAt(h, in int, lock, expiry uint32, ts time.Time) *tx.Tx {
+	var vin tx.Vin
+	copy(vin[:], cbase.KeyID(h, 0, in))
+	pkh := bytes.Repeat([]byte{1}, 20)
+
+	body := tx.NewBody([]tx.Vin{vin}, []tx.Vout{
+		tx.NewCoinOut(&tx.Coin{Receiver: pkh, Amount: 1000}),
+	})
+	return tx.New(tx.Header{
+		Version:    tx.Version,
+		Timestamp:  ts.UnixMilli(),
+		Minter:     pkh,
+		LockHeight: lock,
+		Expiry:     expiry,
+	}, body)
+}
+
+func newPool(height int) *Pool {
+	return New(height, Options{HoldAhead: 50, Now: func() time.Time { return testNow }})
+}
+
+func TestAdd(t *testing.T) {
+	p := newPool(100)
+	a := testTx(10, 0, 0, 0)
+
+	if err := p.Add(a); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		tx   *tx.Tx
+		want error
+	}{
+		{a, ErrExists},
+		{testTx(10, 0, 0, 500), ErrConflict},
+		{testTx(10, 1, 0, 100), tx.ErrExpired},
+		{testTx(10, 2, 200, 0), ErrTooFar},
+	}
+	for i, tt := range tests {
+		if err := p.Add(tt.tx); !errors.Is(err, tt.want) {
+			t.Errorf("%d: got %v, want %v", i, err, tt.want)
+		}
+	}
+	if p.Len() != 1 {
+		t.Errorf("Len: got %d, want 1", p.Len())
+	}
+}
+
+func TestHoldAndExpire(t *testing.T) {
+	p := newPool(100)
+	held := testTx(10, 0, 103, 0)
+	short := testTx(10, 1, 0, 102)
+
+	for _, x := range []*tx.Tx{held, short} {
+		if err := p.Add(x); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(p.Held()) != 1 || len(p.Ready()) != 1 {
+		t.Fatalf("held %d, ready %d", len(p.Held()), len(p.Ready()))
+	}
+	// 101：下一高度 102，持有的仍锁定
+	b := &block.Block{Height: 101}
+	if dropped := p.Connect(b); len(dropped) != 0 {
+		t.Errorf("101: dropped %d", len(dropped))
+	}
+	// 102：下一高度 103，持有的解锁，短期的失效
+	b = &block.Block{Height: 102, Prev: b.Hash()}
+	dropped := p.Connect(b)
+
+	if len(dropped) != 1 || dropped[0].ID() != short.ID() {
+		t.Errorf("102: dropped %v", dropped)
+	}
+	if r := p.Ready(); len(r) != 1 || r[0].ID() != held.ID() {
+		t.Errorf("102: ready %v", r)
+	}
+}
+
+func TestConnectDisconnect(t *testing.T) {
+	p := newPool(100)
+	a := testTx(10, 0, 0, 0)
+
+	if err := p.Add(a); err != nil {
+		t.Fatal(err)
+	}
+	// 区块打包了冲突的交易
+	rival := testTx(10, 0, 0, 900)
+	b := &block.Block{Height: 101, Txs: []*tx.Tx{rival}}
+
+	if dropped := p.Connect(b); len(dropped) != 1 || dropped[0].ID() != a.ID() {
+		t.Fatalf("dropped %v", dropped)
+	}
+	// 断开后区块中的交易回到池中
+	p.Disconnect(b)
+	if p.Height() != 100 || p.Get(rival.ID()) == nil {
+		t.Errorf("after disconnect: height %d, len %d", p.Height(), p.Len())
+	}
+}
+
+// 锁定中的交易同样检查时间戳。
+func TestAddLockedFuture(t *testing.T) {
+	p := newPool(100)
+	x := testTxAt(10, 0, 102, 0, testNow.AddDate(1, 0, 0))
+
+	if err := p.Add(x); !errors.Is(err, tx.ErrFutureTime) {
+		t.Fatalf("got %v, want ErrFutureTime", err)
+	}
+	p.Connect(&block.Block{Height: 101})
+
+	if r := p.Ready(); len(r) != 0 {
+		t.Errorf("ready %v", r)
+	}
+}
+
+// Ready 按当前时间重新检查。
+func TestReadyRecheck(t *testing.T) {
+	now := testNow
+	p := New(100, Options{Now: func() time.Time { return now }})
+	x := testTxAt(10, 0, 0, 0, testNow.Add(time.Hour))
+
+	if err := p.Add(x); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Ready()) != 1 {
+		t.Fatal("not ready")
+	}
+	// 时钟回拨后时间戳超前
+	now = testNow.Add(-2 * time.Hour)
+	if r := p.Ready(); len(r) != 0 {
+		t.Errorf("ready %v after clock change", r)
+	}
+	if h := p.Held(); len(h) != 0 {
+		t.Errorf("held %v", h)
+	}
+	now = testNow
+	if len(p.Ready()) != 1 {
+		t.Error("not ready after clock restored")
+	}
+}
@@ -7,19 +7,16 @@ package paddr
 import (
 	"bytes"
 	"crypto/sha256"
-	"errors"
 	"strings"
 
+	"github.com/cxio/cbase"
 	"github.com/cxio/cbase/base58"
+	"github.com/cxio/cbase/cerror"
 	"github.com/cxio/cbase/chash"
-	"github.com/cxio/locale"
 	"golang.org/x/crypto/blake2b"
 	"golang.org/x/crypto/sha3"
 )
 
-// 便捷引用。
-var _T = locale.GetText
-
 const (
 	// 地址前缀分隔符。
 	// 固定标识，可用于剥离前缀。
@@ -34,19 +31,19 @@ const (
 
 var (
 	// 多重签名条目数错误。
-	ErrMSigSize = errors.New(_T("多重签名条目数超出上限（255）"))
+	ErrMSigSize = cerror.New(1001, "多重签名条目数超出上限（255）")
 
 	// 多重签名公钥序位错误。
-	ErrMSigIndex = errors.New(_T("多重签名公钥序位空缺错误"))
+	ErrMSigIndex = cerror.New(1002, "多重签名公钥序位空缺错误")
 
 	// 前缀分隔符错误。
-	ErrDelimMissing = errors.New(_T("账户地址无标识前缀分隔符"))
+	ErrDelimMissing = cerror.New(1003, "账户地址无标识前缀分隔符")
 
 	// 校验错误。
-	ErrChecksum = errors.New(_T("地址校验错误"))
+	ErrChecksum = cerror.New(1004, "地址校验错误")
 
 	// 无效格式。
-	ErrInvalidFormat = errors.New(_T("无效的格式：缺失校验码"))
+	ErrInvalidFormat = cerror.New(1005, "无效的格式：缺失校验码")
 )
 
 // 公钥地址。
@@ -72,6 +69,7 @@ func Hash(pubKey, prefix []byte) PKAddr {
 // - pkhs 为剩余未签名公钥地址集。
 // 规则：
 // 两个集合中字节序列成员内的首字节为位置序号。
+// 序号须在 [0, T) 范围内且不重复，否则返回 ErrMSigIndex。
 // n/T 配比各占1字节，参与哈希构造，放置在公钥地址清单前端。
 // 返回的总公钥地址也前置 n/T 配比（明码）。
 // 注：
@@ -86,10 +84,18 @@ func MulHash(pks [][]byte, pkhs [][]byte) (PKAddr, error) {
 	all := make([][]byte, t)
 
 	for _, pk := range pks {
-		all[int(pk[0])] = Hash(pk[1:], nil)
+		i, err := msigIndex(pk, all)
+		if err != nil {
+			return nil, err
+		}
+		all[i] = Hash(pk[1:], nil)
 	}
 	for _, pkh := range pkhs {
-		all[int(pkh[0])] = pkh[1:]
+		i, err := msigIndex(pkh, all)
+		if err != nil {
+			return nil, err
+		}
+		all[i] = pkh[1:]
 	}
 
 	return hashMPKH(all, n)
@@ -106,13 +112,25 @@ func MulHash(pks [][]byte, pkhs [][]byte) (PKAddr, error) {
 // 4. 附上识别前缀，即“前缀:文本地址”即为账户地址。
 // 注：
 // 前缀分隔符（:）为系统设置。
+// 校验方案由前缀关联的版本决定（默认 CheckV1，即上述方式），参见 SetPrefixCheck。
 func Encode(pkh []byte, prefix string) string {
+	// 已关联的版本必然有效
+	s, _ := EncodeVer(pkh, prefix, PrefixCheck(prefix))
+	return s
+}
+
+// EncodeVer 以指定校验方案编码账户地址。
+// 编码方式同 Encode，校验码的计算和长度由 ver 决定。
+func EncodeVer(pkh []byte, prefix string, ver int) (string, error) {
+	cs, ok := checkSchemes[ver]
+	if !ok {
+		return "", ErrCheckVersion
+	}
 	pf := []byte(prefix)
 
 	// 前缀+公钥地址
-	b := nbytes(len(pkh)+4, pf...)
-	b = append(b, pkh...)
-	chsum := checksum(b)
+	b := cbase.ConcatBytes(pf, pkh)
+	chsum := cs.sum(b)
 
 	// 文本地址
 	buf := bytes.NewBuffer(pf)
@@ -120,9 +138,9 @@ func Encode(pkh []byte, prefix string) string {
 
 	buf.WriteString(base58.Encode(
 		// 公钥地址（无前缀）+校验码
-		append(b[len(pf):], chsum[:]...),
+		append(b[len(pf):], chsum...),
 	))
-	return buf.String()
+	return buf.String(), nil
 }
 
 // Decode 账户地址解码为公钥地址。
@@ -130,45 +148,47 @@ func Encode(pkh []byte, prefix string) string {
 // 2. 将文本地址解码为字节序列。末尾4字节为校验码，前段为公钥地址。
 // 3. 公钥地址前置识别前缀（即“前缀+公钥地址”），执行两次哈希运算取末尾4字节为校验码。
 // 4. 比较上面两个校验码，相同则地址合法。
+// 注：
+// 各版本的校验方案都会被尝试，参见 DecodeVer。
 func Decode(addr string) ([]byte, string, error) {
+	pkh, pf, _, err := DecodeVer(addr)
+	return pkh, pf, err
+}
+
+// DecodeVer 账户地址解码为公钥地址，同时返回校验方案版本。
+// 按校验码由长到短依次尝试各版本，首个校验通过的即为结果。
+// 注：
+// 新版本的校验计算包含版本标识，旧版地址不会误判为新版。
+func DecodeVer(addr string) ([]byte, string, int, error) {
 	// 前缀提取
 	i := strings.IndexByte(addr, Delimiter)
 	if i < 0 {
-		return nil, "", ErrDelimMissing
+		return nil, "", 0, ErrDelimMissing
 	}
 	pf, at := addr[:i], addr[i+1:]
 	// 解码
 	bs := base58.Decode(at)
 	if len(bs) < 5 {
-		return nil, "", ErrInvalidFormat
+		return nil, "", 0, ErrInvalidFormat
 	}
-	// 校验码处理
-	pkh := bs[:len(bs)-4]
-	var cksum [4]byte
-	copy(cksum[:], bs[len(bs)-4:])
-
-	// 验证码验证
-	if checksum(append([]byte(pf), pkh...)) != cksum {
-		return nil, "", ErrChecksum
+	for _, ver := range checkOrder {
+		cs := checkSchemes[ver]
+		n := len(bs) - cs.size
+		if n < 1 {
+			continue
+		}
+		// 验证码验证
+		if bytes.Equal(cs.sum(append([]byte(pf), bs[:n]...)), bs[n:]) {
+			return cbase.ConcatBytes(bs[:n]), pf, ver, nil
+		}
 	}
-	return nbytes(0, pkh...), pf, nil
+	return nil, "", 0, ErrChecksum
 }
 
 //
 // 私有辅助
 ///////////////////////////////////////////////////////////////////////////////
 
-// 创建一个新字节序列。
-// n 为额外空间（除bs外）。
-// bs 为立即添加的字节数据。
-// 用途：
-// - 返回新的空间占用刚好的字节序列。
-// - 初始即创建大小刚好的空间。
-func nbytes(n int, bs ...byte) []byte {
-	b := make([]byte, 0, n+len(bs))
-	return append(b, bs...)
-}
-
 // 获取地址编码校验码。
 // 取“前缀+公钥地址”两次哈希后末尾4字节。
 func checksum(fpkh []byte) (cksum [4]byte) {
@@ -178,23 +198,35 @@ func checksum(fpkh []byte) (cksum [4]byte) {
 	return
 }
 
+// 提取多重签名成员的位置序号。
+// 成员为空、序号越界或位置已被占用时返回 ErrMSigIndex。
+func msigIndex(item []byte, all [][]byte) (int, error) {
+	if len(item) == 0 {
+		return 0, ErrMSigIndex
+	}
+	i := int(item[0])
+
+	if i >= len(all) || all[i] != nil {
+		return 0, ErrMSigIndex.With(i)
+	}
+	return i, nil
+}
+
 // 计算多重签名总的公钥哈希。
 // pkhs 为公钥地址集，以按需要的顺序排列。
 // n 为需要的最少签名数量。
 // 各公钥地址串联，前置 n/T 配比后计算哈希。
 // 返回值：总公钥哈希。
 func hashMPKH(pkhs [][]byte, n int) (PKAddr, error) {
-	t := len(pkhs)
-	_n := byte(n)
-	_t := byte(t)
-	buf := nbytes(t*HashSize, _n, _t)
+	nt := []byte{byte(n), byte(len(pkhs))}
 
-	for _, pkh := range pkhs {
-		if pkh == nil {
+	for _, b := range pkhs {
+		if b == nil {
 			return nil, ErrMSigIndex
 		}
-		buf = append(buf, pkh...)
 	}
+	buf := cbase.ConcatBytes(append([][]byte{nt}, pkhs...)...)
+
 	// 前置 n/T 明码友好
-	return Hash(buf, []byte{_n, _t}), nil
+	return Hash(buf, nt), nil
 }
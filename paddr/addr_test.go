@@ -0,0 +1,30 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package paddr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMulHashIndex(t *testing.T) {
+	pkh := append([]byte{0}, make([]byte, HashSize)...)
+
+	tests := []struct {
+		pks, pkhs [][]byte
+	}{
+		{[][]byte{{5, 1}}, nil},
+		{[][]byte{{0, 1}, {0, 2}}, nil},
+		{[][]byte{{1, 1}}, [][]byte{{1, 2}}},
+		{[][]byte{{}}, nil},
+	}
+	for i, tt := range tests {
+		if _, err := MulHash(tt.pks, tt.pkhs); !errors.Is(err, ErrMSigIndex) {
+			t.Errorf("%d: got %v, want ErrMSigIndex", i, err)
+		}
+	}
+	if _, err := MulHash([][]byte{{1, 1}}, [][]byte{pkh}); err != nil {
+		t.Errorf("valid: %v", err)
+	}
+}
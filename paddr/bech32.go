@@ -0,0 +1,278 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package paddr
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/cxio/cbase/cerror"
+)
+
+// Bech32m 编码
+// 作为 Base58 地址之外的另一种编码形式，采用BCH校验码，不仅能检测错误，
+// 还可以定位少量（最多2个）字符的替换错误。
+// 格式：<可读部分>1<数据部分><6字符校验码>
+// 可读部分即为小写的标识前缀。整个地址大小写不敏感，但不可大小写混用。
+
+const (
+	// Bech32 字符集。
+	bechCharset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+	// 可读部分与数据部分的分隔符。
+	BechSeparator = '1'
+
+	// Bech32m 校验常量。
+	bechConst = 0x2bc830a3
+
+	// 校验码长度（字符）。
+	bechChecksumLen = 6
+
+	// 地址总长度上限。
+	bechMaxLen = 90
+
+	// 可定位的错误数量上限。
+	BechMaxErrors = 2
+)
+
+var (
+	// 前缀不适用于Bech32m编码。
+	ErrBechPrefix = cerror.New(1301, "标识前缀不适用于Bech32m编码")
+
+	// Bech32m 格式错误。
+	ErrBechFormat = cerror.New(1302, "无效的Bech32m地址格式")
+
+	// 大小写混用。
+	ErrBechCase = cerror.New(1303, "Bech32m地址大小写混用")
+
+	// 无法定位错误。
+	ErrBechLocate = cerror.New(1304, "错误过多，无法定位")
+)
+
+// 校验码生成多项式。
+var bechGen = [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+
+// 字符到值的映射，-1 表示非法。
+var bechIndex [128]int8
+
+func init() {
+	for i := range bechIndex {
+		bechIndex[i] = -1
+	}
+	for i, c := range bechCharset {
+		bechIndex[c] = int8(i)
+	}
+}
+
+// EncodeBech 公钥地址编码为Bech32m格式的账户地址。
+// 可读部分为标识前缀的小写形式。
+// 前缀须为可打印ASCII字符（不含空格），否则返回 ErrBechPrefix。
+func EncodeBech(pkh []byte, prefix string) (string, error) {
+	hrp := strings.ToLower(prefix)
+	data := convertBits(pkh, 8, 5, true)
+
+	if !validHRP(hrp) || len(hrp)+1+len(data)+bechChecksumLen > bechMaxLen {
+		return "", ErrBechPrefix
+	}
+	var b strings.Builder
+	b.WriteString(hrp)
+	b.WriteByte(BechSeparator)
+
+	for _, v := range append(data, bechChecksum(hrp, data)...) {
+		b.WriteByte(bechCharset[v])
+	}
+	return b.String(), nil
+}
+
+// DecodeBech 解码Bech32m格式的账户地址。
+// 大小写不敏感，返回公钥地址和（小写的）标识前缀。
+func DecodeBech(addr string) ([]byte, string, error) {
+	hrp, data, err := splitBech(addr)
+	if err != nil {
+		return nil, "", err
+	}
+	if bechPolymod(hrpValues(hrp, data)) != bechConst {
+		return nil, "", ErrChecksum
+	}
+	pkh := convertBits(data[:len(data)-bechChecksumLen], 5, 8, false)
+	if pkh == nil {
+		return nil, "", ErrBechFormat
+	}
+	return pkh, hrp, nil
+}
+
+// LocateBechErrors 定位Bech32m地址中的替换错误。
+// 返回出错字符在地址中的位置（从0开始，升序），最多 BechMaxErrors 个。
+// 地址校验无误时返回nil，错误过多无法定位时返回 ErrBechLocate。
+// 注：
+// 仅能定位数据部分（含校验码）的错误，可读部分须正确。
+func LocateBechErrors(addr string) ([]int, error) {
+	hrp, data, err := splitBech(addr)
+	if err != nil {
+		return nil, err
+	}
+	res := bechPolymod(hrpValues(hrp, data)) ^ bechConst
+	if res == 0 {
+		return nil, nil
+	}
+	// 各位置、各差值的校正子
+	type fix struct{ pos, val int }
+	n := len(data)
+	syn := make(map[uint32]fix, n*31)
+	list := make([]uint32, 0, n*31)
+
+	for p := 0; p < n; p++ {
+		for v := 1; v < 32; v++ {
+			s := bechSyndrome(v, n-1-p)
+			syn[s] = fix{p, v}
+			list = append(list, s)
+		}
+	}
+	at := len(hrp) + 1
+
+	if f, ok := syn[res]; ok {
+		return []int{at + f.pos}, nil
+	}
+	for _, s := range list {
+		f1 := syn[s]
+		if f2, ok := syn[res^s]; ok && f2.pos != f1.pos {
+			out := []int{at + f1.pos, at + f2.pos}
+			sort.Ints(out)
+			return out, nil
+		}
+	}
+	return nil, ErrBechLocate
+}
+
+//
+// 私有辅助
+///////////////////////////////////////////////////////////////////////////////
+
+// 拆分地址为可读部分和数据值（含校验码）。
+// 检查长度、大小写、字符合法性。
+func splitBech(addr string) (string, []byte, error) {
+	if len(addr) > bechMaxLen {
+		return "", nil, ErrBechFormat
+	}
+	lower, upper := strings.ToLower(addr), strings.ToUpper(addr)
+	if addr != lower && addr != upper {
+		return "", nil, ErrBechCase
+	}
+	addr = lower
+
+	i := strings.LastIndexByte(addr, BechSeparator)
+	if i < 1 || i+1+bechChecksumLen > len(addr) {
+		return "", nil, ErrBechFormat
+	}
+	hrp := addr[:i]
+	if !validHRP(hrp) {
+		return "", nil, ErrBechPrefix
+	}
+	data := make([]byte, 0, len(addr)-i-1)
+
+	for _, c := range []byte(addr[i+1:]) {
+		if c >= 128 || bechIndex[c] < 0 {
+			return "", nil, ErrBechFormat
+		}
+		data = append(data, byte(bechIndex[c]))
+	}
+	return hrp, data, nil
+}
+
+// 可读部分是否合法。
+func validHRP(hrp string) bool {
+	if hrp == "" {
+		return false
+	}
+	for i := 0; i < len(hrp); i++ {
+		if hrp[i] < 33 || hrp[i] > 126 {
+			return false
+		}
+	}
+	return true
+}
+
+// 可读部分展开后串联数据值。
+func hrpValues(hrp string, data []byte) []byte {
+	out := make([]byte, 0, len(hrp)*2+1+len(data))
+
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]>>5)
+	}
+	out = append(out, 0)
+
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]&31)
+	}
+	return append(out, data...)
+}
+
+// 计算校验码（6个值）。
+func bechChecksum(hrp string, data []byte) []byte {
+	vs := append(hrpValues(hrp, data), make([]byte, bechChecksumLen)...)
+	mod := bechPolymod(vs) ^ bechConst
+	out := make([]byte, bechChecksumLen)
+
+	for i := range out {
+		out[i] = byte(mod>>uint(5*(5-i))) & 31
+	}
+	return out
+}
+
+// BCH 校验多项式求余。
+func bechPolymod(values []byte) uint32 {
+	chk := uint32(1)
+	for _, v := range values {
+		chk = bechStep(chk, v)
+	}
+	return chk
+}
+
+// 多项式求余的单步运算。
+func bechStep(chk uint32, v byte) uint32 {
+	top := chk >> 25
+	chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+
+	for i, g := range bechGen {
+		if (top>>uint(i))&1 != 0 {
+			chk ^= g
+		}
+	}
+	return chk
+}
+
+// 单个错误的校正子。
+// 即差值 v 之后跟随 k 个零值时，对求余结果的影响（线性部分）。
+func bechSyndrome(v, k int) uint32 {
+	chk := bechStep(0, byte(v))
+	for i := 0; i < k; i++ {
+		chk = bechStep(chk, 0)
+	}
+	return chk
+}
+
+// 位宽转换。
+// pad 为真时末尾不足的位补零；为假时不容许非零的填充位，出错返回nil。
+func convertBits(data []byte, from, to uint, pad bool) []byte {
+	var acc, bits uint
+	max := uint(1)<<to - 1
+	out := make([]byte, 0, len(data)*int(from)/int(to)+1)
+
+	for _, v := range data {
+		acc = acc<<from | uint(v)
+		bits += from
+		for bits >= to {
+			bits -= to
+			out = append(out, byte(acc>>bits&max))
+		}
+	}
+	if pad {
+		if bits > 0 {
+			out = append(out, byte(acc<<(to-bits)&max))
+		}
+	} else if bits >= from || acc<<(to-bits)&max != 0 {
+		return nil
+	}
+	return out
+}
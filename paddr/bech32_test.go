@@ -0,0 +1,84 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package paddr
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// BIP-350 中的有效 Bech32m 字符串。
+var bechValid = []string{
+	"A1LQFN3A",
+	"a1lqfn3a",
+	"abcdef1l7aum6echk45nj3s0wdvt2fg8x9yrzpqzd3ryx",
+	"split1checkupstagehandshakeupstreamerranterredcaperredlc445v",
+	"?1v759aa",
+}
+
+func TestBechChecksum(t *testing.T) {
+	for _, s := range bechValid {
+		hrp, data, err := splitBech(s)
+		if err != nil {
+			t.Errorf("splitBech(%q): %v", s, err)
+			continue
+		}
+		if bechPolymod(hrpValues(hrp, data)) != bechConst {
+			t.Errorf("%q: checksum mismatch", s)
+		}
+	}
+	if _, _, err := splitBech("A1lqfn3a"); err != ErrBechCase {
+		t.Errorf("mixed case: got %v, want ErrBechCase", err)
+	}
+}
+
+func TestBechRoundTrip(t *testing.T) {
+	pkh := Hash([]byte("public key"), nil)
+
+	addr, err := EncodeBech(pkh, "CX")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(addr, "cx1") {
+		t.Errorf("EncodeBech: got %q, want cx1 prefix", addr)
+	}
+	for _, s := range []string{addr, strings.ToUpper(addr)} {
+		got, pf, err := DecodeBech(s)
+		if err != nil || pf != "cx" || !bytes.Equal(got, pkh) {
+			t.Errorf("DecodeBech(%q): got %x, %q, %v", s, got, pf, err)
+		}
+	}
+	if _, err := EncodeBech(pkh, "c x"); err != ErrBechPrefix {
+		t.Errorf("EncodeBech bad prefix: got %v, want ErrBechPrefix", err)
+	}
+}
+
+func TestLocateBechErrors(t *testing.T) {
+	addr, _ := EncodeBech(Hash([]byte("public key"), nil), "cx")
+
+	// 替换指定位置的字符
+	subst := func(s string, pos ...int) string {
+		b := []byte(s)
+		for _, p := range pos {
+			i := strings.IndexByte(bechCharset, b[p])
+			b[p] = bechCharset[(i+7)%32]
+		}
+		return string(b)
+	}
+	if pos, err := LocateBechErrors(addr); pos != nil || err != nil {
+		t.Errorf("valid address: got %v, %v", pos, err)
+	}
+	for _, want := range [][]int{{3}, {10}, {len(addr) - 1}, {4, 20}, {5, len(addr) - 2}} {
+		bad := subst(addr, want...)
+		if _, _, err := DecodeBech(bad); err != ErrChecksum {
+			t.Errorf("DecodeBech(%q): got %v, want ErrChecksum", bad, err)
+		}
+		pos, err := LocateBechErrors(bad)
+		if err != nil || !reflect.DeepEqual(pos, want) {
+			t.Errorf("LocateBechErrors(%q): got %v, %v, want %v", bad, pos, err, want)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package paddr
+
+import (
+	"sync"
+
+	"github.com/cxio/cbase/cerror"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/sha3"
+)
+
+// 地址校验方案版本。
+const (
+	// 原方案：SHA256 后 BLAKE2b，取末尾4字节。
+	CheckV1 = 1
+
+	// 长校验：带版本标识的 SHA3-256 后 BLAKE2b，取末尾8字节。
+	// 用于高价值的地址类型。
+	CheckV2 = 2
+)
+
+// 校验方案版本无效。
+var ErrCheckVersion = cerror.New(1101, "无效的地址校验方案版本")
+
+// 校验方案。
+type checkScheme struct {
+	size int                      // 校验码长度
+	sum  func(fpkh []byte) []byte // 校验码计算
+}
+
+// 校验方案集。
+var checkSchemes = map[int]checkScheme{
+	CheckV1: {4, func(b []byte) []byte { c := checksum(b); return c[:] }},
+	CheckV2: {8, func(b []byte) []byte { c := checksum2(b); return c[:] }},
+}
+
+// 解码时的尝试顺序（校验码由长到短）。
+var checkOrder = []int{CheckV2, CheckV1}
+
+// 前缀关联的校验方案版本。
+var prefixChecks = struct {
+	sync.RWMutex
+	m map[string]int
+}{m: make(map[string]int)}
+
+// SetPrefixCheck 设置前缀默认使用的校验方案。
+// Encode 对该前缀的地址将采用此方案，解码不受影响（自动识别）。
+func SetPrefixCheck(prefix string, ver int) error {
+	if _, ok := checkSchemes[ver]; !ok {
+		return ErrCheckVersion
+	}
+	prefixChecks.Lock()
+	defer prefixChecks.Unlock()
+
+	prefixChecks.m[prefix] = ver
+	return nil
+}
+
+// PrefixCheck 获取前缀关联的校验方案版本。
+// 未设置的前缀返回 CheckV1。
+func PrefixCheck(prefix string) int {
+	prefixChecks.RLock()
+	defer prefixChecks.RUnlock()
+
+	if v, ok := prefixChecks.m[prefix]; ok {
+		return v
+	}
+	return CheckV1
+}
+
+// 获取地址编码长校验码（CheckV2）。
+// 取“版本标识+前缀+公钥地址”两次哈希后末尾8字节。
+func checksum2(fpkh []byte) (cksum [8]byte) {
+	h1 := sha3.Sum256(append([]byte{CheckV2}, fpkh...))
+	h2 := blake2b.Sum256(h1[:])
+	copy(cksum[:], h2[len(h2)-8:])
+	return
+}
@@ -0,0 +1,48 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package paddr
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCheckVersions(t *testing.T) {
+	pkh := Hash([]byte("public key"), nil)
+
+	for _, ver := range []int{CheckV1, CheckV2} {
+		addr, err := EncodeVer(pkh, "cx", ver)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, pf, v, err := DecodeVer(addr)
+		if err != nil || v != ver || pf != "cx" || !bytes.Equal(got, pkh) {
+			t.Errorf("DecodeVer(%q): got %x, %q, %d, %v", addr, got, pf, v, err)
+		}
+	}
+	if _, err := EncodeVer(pkh, "cx", 9); err != ErrCheckVersion {
+		t.Errorf("EncodeVer unknown: got %v, want ErrCheckVersion", err)
+	}
+}
+
+func TestPrefixCheck(t *testing.T) {
+	pkh := Hash([]byte("public key"), nil)
+	old := Encode(pkh, "hv")
+
+	if err := SetPrefixCheck("hv", CheckV2); err != nil {
+		t.Fatal(err)
+	}
+	defer SetPrefixCheck("hv", CheckV1)
+
+	addr := Encode(pkh, "hv")
+	if addr == old {
+		t.Fatal("Encode: prefix check version not applied")
+	}
+	// 新旧两种形式都能解码
+	for _, s := range []string{old, addr} {
+		if got, _, err := Decode(s); err != nil || !bytes.Equal(got, pkh) {
+			t.Errorf("Decode(%q): got %x, %v", s, got, err)
+		}
+	}
+}
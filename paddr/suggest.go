@@ -0,0 +1,150 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package paddr
+
+import (
+	"strings"
+)
+
+// 修正建议的默认解码尝试上限。
+const SuggestLimit = 5000
+
+// Base58 字母表（同 base58 包）。
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// 易混淆字符映射。
+// 键为 Base58 不使用的字符，值为可能的本意字符。
+var confusables = map[byte]string{
+	'0': "o",
+	'O': "o",
+	'I': "1i",
+	'l': "1i",
+}
+
+// 易混淆字符组合的数量上限。
+const maxConfusions = 16
+
+// Suggest 为校验失败的账户地址提供修正建议。
+// 依次尝试：易混淆字符（0/O/I/l）的替换、相邻字符换位、单字符替换，
+// 返回校验通过的候选地址，按尝试顺序排列，最多 max 个。
+// limit 为解码尝试次数上限，<=0 时取 SuggestLimit。
+// 注：
+// 仅修正文本地址部分，前缀须正确。地址本身有效时原样返回。
+func Suggest(addr string, max, limit int) []string {
+	i := strings.IndexByte(addr, Delimiter)
+	if i < 0 || max <= 0 {
+		return nil
+	}
+	if limit <= 0 {
+		limit = SuggestLimit
+	}
+	s := &suggester{
+		prefix: addr[:i+1],
+		max:    max,
+		limit:  limit,
+		seen:   make(map[string]bool),
+	}
+	bases, bad := normalize(addr[i+1:])
+
+	for _, b := range bases {
+		if bad < 0 {
+			s.try(b)
+			s.transpose(b)
+		}
+		s.substitute(b, bad)
+	}
+	return s.out
+}
+
+// 修正尝试器。
+type suggester struct {
+	prefix string          // 前缀（含分隔符）
+	max    int             // 候选数量上限
+	limit  int             // 剩余尝试次数
+	seen   map[string]bool // 已尝试的文本地址
+	out    []string        // 候选地址
+}
+
+// 是否应停止尝试。
+func (s *suggester) done() bool {
+	return s.limit <= 0 || len(s.out) >= s.max
+}
+
+// 尝试一个文本地址。
+func (s *suggester) try(at string) {
+	if s.done() || s.seen[at] {
+		return
+	}
+	s.seen[at] = true
+	s.limit--
+
+	if _, _, err := Decode(s.prefix + at); err == nil {
+		s.out = append(s.out, s.prefix+at)
+	}
+}
+
+// 尝试相邻字符换位。
+func (s *suggester) transpose(at string) {
+	b := []byte(at)
+
+	for i := 0; i+1 < len(b) && !s.done(); i++ {
+		if b[i] == b[i+1] {
+			continue
+		}
+		b[i], b[i+1] = b[i+1], b[i]
+		s.try(string(b))
+		b[i], b[i+1] = b[i+1], b[i]
+	}
+}
+
+// 尝试单字符替换。
+// pos 为非负时仅替换该位置（非法字符处），否则逐个位置尝试。
+func (s *suggester) substitute(at string, pos int) {
+	b := []byte(at)
+
+	for i := range b {
+		if pos >= 0 && i != pos {
+			continue
+		}
+		c := b[i]
+		for j := 0; j < len(base58Alphabet) && !s.done(); j++ {
+			if b[i] = base58Alphabet[j]; b[i] != c {
+				s.try(string(b))
+			}
+		}
+		b[i] = c
+	}
+}
+
+// 规范化文本地址。
+// 将易混淆字符替换为可能的本意字符，返回各种组合（数量有限）。
+// bad 为剩余的唯一非法字符位置，无则为-1。存在多个无法处理的非法字符时返回空集。
+func normalize(at string) (bases []string, bad int) {
+	bases = []string{""}
+	bad = -1
+
+	for i := 0; i < len(at); i++ {
+		c := at[i]
+		alt, ok := confusables[c]
+
+		switch {
+		case ok:
+		case strings.IndexByte(base58Alphabet, c) >= 0:
+			alt = string(c)
+		case bad < 0:
+			bad, alt = i, string(c)
+		default:
+			return nil, -1
+		}
+		next := make([]string, 0, len(bases)*len(alt))
+
+		for _, b := range bases {
+			for j := 0; j < len(alt) && len(next) < maxConfusions; j++ {
+				next = append(next, b+alt[j:j+1])
+			}
+		}
+		bases = next
+	}
+	return bases, bad
+}
@@ -0,0 +1,40 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package paddr
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSuggest(t *testing.T) {
+	addr := Encode(Hash([]byte("public key"), nil), "cx")
+	at := len("cx:")
+
+	tests := []struct {
+		name string
+		edit func(b []byte)
+	}{
+		{"substitution", func(b []byte) { b[at+3] = nextChar(b[at+3]) }},
+		{"transposition", func(b []byte) { b[at+5], b[at+6] = b[at+6], b[at+5] }},
+		{"invalid char", func(b []byte) { b[at+7] = '#' }},
+	}
+	for _, x := range tests {
+		b := []byte(addr)
+		x.edit(b)
+		if string(b) == addr {
+			continue
+		}
+		got := Suggest(string(b), 3, 0)
+		if len(got) == 0 || got[0] != addr {
+			t.Errorf("%s: Suggest(%q) = %v, want %q first", x.name, b, got, addr)
+		}
+	}
+}
+
+// Base58 字母表中的下一个字符。
+func nextChar(c byte) byte {
+	i := strings.IndexByte(base58Alphabet, c)
+	return base58Alphabet[(i+1)%len(base58Alphabet)]
+}
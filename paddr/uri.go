@@ -0,0 +1,123 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package paddr
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/cxio/cbase/cerror"
+)
+
+const (
+	// 支付URI协议名。
+	URIScheme = "cxpay"
+
+	// 每币聪数。
+	coinUnit = 1e8
+)
+
+var (
+	// URI协议名错误。
+	ErrURIScheme = cerror.New(1201, "支付URI协议名错误")
+
+	// URI金额格式错误。
+	ErrURIAmount = cerror.New(1202, "支付URI金额格式错误")
+)
+
+// URI 支付请求URI。
+// 格式：cxpay:<账户地址>?amount=<币量>&label=<标签>&message=<附言>
+// 币量为十进制币数（最多8位小数），各参数可选。
+type URI struct {
+	Address string // 账户地址（含前缀）
+	Amount  int64  // 金额（聪），0表示未指定
+	Label   string // 收款方标签
+	Message string // 附言
+}
+
+// String 编码为URI文本。
+func (u *URI) String() string {
+	q := url.Values{}
+
+	if u.Amount > 0 {
+		q.Set("amount", formatAmount(u.Amount))
+	}
+	if u.Label != "" {
+		q.Set("label", u.Label)
+	}
+	if u.Message != "" {
+		q.Set("message", u.Message)
+	}
+	s := URIScheme + ":" + u.Address
+
+	if len(q) > 0 {
+		s += "?" + q.Encode()
+	}
+	return s
+}
+
+// ParseURI 解析支付URI。
+// 会对其中的账户地址执行解码校验。
+func ParseURI(s string) (*URI, error) {
+	if !strings.HasPrefix(s, URIScheme+":") {
+		return nil, ErrURIScheme
+	}
+	s = s[len(URIScheme)+1:]
+	addr, query := s, ""
+
+	if i := strings.IndexByte(s, '?'); i >= 0 {
+		addr, query = s[:i], s[i+1:]
+	}
+	if _, _, err := Decode(addr); err != nil {
+		return nil, err
+	}
+	q, err := url.ParseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	u := &URI{
+		Address: addr,
+		Label:   q.Get("label"),
+		Message: q.Get("message"),
+	}
+	if v := q.Get("amount"); v != "" {
+		if u.Amount, err = parseAmount(v); err != nil {
+			return nil, err
+		}
+	}
+	return u, nil
+}
+
+// 聪数格式化为币数文本。
+// 省略小数部分末尾的0。
+func formatAmount(n int64) string {
+	s := strconv.FormatInt(n/coinUnit, 10)
+	f := strconv.FormatInt(n%coinUnit+coinUnit, 10)[1:]
+
+	if f = strings.TrimRight(f, "0"); f != "" {
+		s += "." + f
+	}
+	return s
+}
+
+// 币数文本解析为聪数。
+// 不经过浮点数转换，以免精度损失。
+func parseAmount(s string) (int64, error) {
+	ip, fp := s, ""
+
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		ip, fp = s[:i], s[i+1:]
+	}
+	if len(fp) > 8 || ip == "" && fp == "" {
+		return 0, ErrURIAmount
+	}
+	fp += strings.Repeat("0", 8-len(fp))
+
+	n, err := strconv.ParseUint(ip+fp, 10, 63)
+	if err != nil || n == 0 {
+		return 0, ErrURIAmount
+	}
+	return int64(n), nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package paddr
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestURIRoundTrip(t *testing.T) {
+	addr := Encode(Hash([]byte("public key"), nil), "cx")
+
+	tests := []URI{
+		{Address: addr},
+		{Address: addr, Amount: 1},
+		{Address: addr, Amount: 1e8},
+		{Address: addr, Amount: 150000000, Label: "Alice"},
+		{Address: addr, Amount: math.MaxInt64},
+		{Address: addr, Label: "张三 & 李四", Message: "a=b?c#d 100%"},
+	}
+	for _, u := range tests {
+		s := u.String()
+		got, err := ParseURI(s)
+		if err != nil {
+			t.Errorf("ParseURI(%q): %v", s, err)
+			continue
+		}
+		if *got != u {
+			t.Errorf("round trip %q: got %+v, want %+v", s, *got, u)
+		}
+	}
+}
+
+func TestURIAmount(t *testing.T) {
+	addr := Encode(Hash([]byte("public key"), nil), "cx")
+
+	tests := []struct {
+		text string
+		want int64
+	}{
+		{"1", 1e8},
+		{"1.5", 150000000},
+		{"0.00000001", 1},
+		{".5", 50000000},
+		{"2.", 2e8},
+		{"92233720368.54775807", math.MaxInt64},
+	}
+	for _, tt := range tests {
+		u, err := ParseURI(URIScheme + ":" + addr + "?amount=" + tt.text)
+		if err != nil || u.Amount != tt.want {
+			t.Errorf("amount %q: got %v, %v, want %d", tt.text, u, err, tt.want)
+		}
+	}
+	// 编码省略末尾的0
+	u := URI{Address: addr, Amount: 150000000}
+	if s := u.String(); !strings.HasSuffix(s, "?amount=1.5") {
+		t.Errorf("String() = %q", s)
+	}
+}
+
+func TestURIEscape(t *testing.T) {
+	addr := Encode(Hash([]byte("public key"), nil), "cx")
+
+	u, err := ParseURI(URIScheme + ":" + addr + "?label=%E5%BC%A0%E4%B8%89&message=pay+for%20tea%26cake")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Label != "张三" || u.Message != "pay for tea&cake" {
+		t.Errorf("got label %q, message %q", u.Label, u.Message)
+	}
+	s := (&URI{Address: addr, Label: "a&b", Message: "x=1 y"}).String()
+	if !strings.Contains(s, "label=a%26b") || !strings.Contains(s, "message=x%3D1+y") {
+		t.Errorf("String() = %q", s)
+	}
+}
+
+func TestURIErrors(t *testing.T) {
+	addr := Encode(Hash([]byte("public key"), nil), "cx")
+	bad := addr[:len(addr)-1] + string(nextChar(addr[len(addr)-1]))
+
+	tests := []struct {
+		name string
+		text string
+		want error
+	}{
+		{"scheme", "bitcoin:" + addr, ErrURIScheme},
+		{"no scheme", addr, ErrURIScheme},
+		{"negative", URIScheme + ":" + addr + "?amount=-1", ErrURIAmount},
+		{"zero", URIScheme + ":" + addr + "?amount=0.0", ErrURIAmount},
+		{"decimals", URIScheme + ":" + addr + "?amount=1.000000001", ErrURIAmount},
+		{"overflow", URIScheme + ":" + addr + "?amount=92233720368.54775808", ErrURIAmount},
+		{"large", URIScheme + ":" + addr + "?amount=100000000000", ErrURIAmount},
+		{"dot only", URIScheme + ":" + addr + "?amount=.", ErrURIAmount},
+		{"exponent", URIScheme + ":" + addr + "?amount=1e3", ErrURIAmount},
+	}
+	for _, tt := range tests {
+		if _, err := ParseURI(tt.text); !errors.Is(err, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.want)
+		}
+	}
+	// 地址校验失败
+	if _, err := ParseURI(URIScheme + ":" + bad + "?amount=1"); err == nil {
+		t.Error("bad address: expected error")
+	}
+	if _, err := ParseURI(URIScheme + ":"); err == nil {
+		t.Error("empty address: expected error")
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package proof
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/binary"
+	"strings"
+)
+
+// 文本封装的边界行。
+const (
+	beginMessage   = "-----BEGIN CXIO SIGNED MESSAGE-----"
+	beginSignature = "-----BEGIN CXIO SIGNATURE-----"
+	endSignature   = "-----END CXIO SIGNATURE-----"
+
+	// 地址头字段。
+	addressHeader = "Address: "
+
+	// 签名数据每行字符数。
+	armorWidth = 64
+)
+
+// Bytes 证明序列化。
+// 格式：版本(1) 标志(1) 签名数 {序位(1) 公钥 签名}... 未签名数 {公钥地址}...
+// 变长数据前置 uvarint 长度。
+func (p *Proof) Bytes() []byte {
+	flag := byte(0)
+	if p.Multi {
+		flag = 1
+	}
+	b := []byte{formatVersion, flag}
+	b = binary.AppendUvarint(b, uint64(len(p.Signers)))
+
+	for _, s := range p.Signers {
+		b = append(b, s.Index)
+		b = appendBytes(b, s.PubKey)
+		b = appendBytes(b, s.Sig)
+	}
+	b = binary.AppendUvarint(b, uint64(len(p.Rest)))
+
+	for _, r := range p.Rest {
+		b = appendBytes(b, r)
+	}
+	return b
+}
+
+// Parse 解码证明数据。
+func Parse(data []byte) (*Proof, error) {
+	if len(data) < 2 || data[0] != formatVersion || data[1] > 1 {
+		return nil, ErrFormat
+	}
+	p := &Proof{Multi: data[1] == 1}
+	r := &reader{buf: data[2:]}
+
+	for n := r.count(); n > 0; n-- {
+		p.Signers = append(p.Signers, Signer{
+			Index:  r.byte(),
+			PubKey: r.bytes(),
+			Sig:    r.bytes(),
+		})
+	}
+	for n := r.count(); n > 0; n-- {
+		p.Rest = append(p.Rest, r.bytes())
+	}
+	if r.bad || len(r.buf) > 0 {
+		return nil, ErrFormat
+	}
+	return p, nil
+}
+
+// Armor 生成文本封装的签名消息。
+// 消息中以 - 开头的行前置 "- " 转义（同 OpenPGP 明文签名）。
+func Armor(addr string, msg []byte, p *Proof) string {
+	var b strings.Builder
+
+	b.WriteString(beginMessage + "\n")
+	for _, line := range strings.Split(string(msg), "\n") {
+		if strings.HasPrefix(line, "-") {
+			b.WriteString("- ")
+		}
+		b.WriteString(line + "\n")
+	}
+	b.WriteString(beginSignature + "\n")
+	b.WriteString(addressHeader + addr + "\n\n")
+
+	sig := base64.StdEncoding.EncodeToString(p.Bytes())
+	for len(sig) > armorWidth {
+		b.WriteString(sig[:armorWidth] + "\n")
+		sig = sig[armorWidth:]
+	}
+	b.WriteString(sig + "\n")
+	b.WriteString(endSignature + "\n")
+
+	return b.String()
+}
+
+// Dearmor 解析文本封装的签名消息。
+// 返回账户地址、消息原文和证明，不执行验证。
+func Dearmor(text string) (addr string, msg []byte, p *Proof, err error) {
+	sc := bufio.NewScanner(strings.NewReader(text))
+	sc.Buffer(nil, 1<<20)
+
+	var lines, sig []string
+	state := 0 // 0: 起始 1: 消息 2: 签名头 3: 签名数据 4: 结束
+
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), "\r")
+
+		switch {
+		case state == 0 && line == beginMessage:
+			state = 1
+		case state == 1 && line == beginSignature:
+			state = 2
+		case state == 1:
+			if strings.HasPrefix(line, "- ") {
+				line = line[2:]
+			}
+			lines = append(lines, line)
+		case state == 2 && strings.HasPrefix(line, addressHeader):
+			addr = strings.TrimSpace(line[len(addressHeader):])
+		case state == 2 && line == "":
+			state = 3
+		case state == 3 && line == endSignature:
+			state = 4
+		case state == 3:
+			sig = append(sig, strings.TrimSpace(line))
+		}
+	}
+	if err = sc.Err(); err != nil {
+		return
+	}
+	if state != 4 || addr == "" {
+		return "", nil, nil, ErrFormat
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.Join(sig, ""))
+	if err != nil {
+		return "", nil, nil, ErrFormat
+	}
+	if p, err = Parse(data); err != nil {
+		return "", nil, nil, err
+	}
+	// 消息末尾的换行为封装所加
+	return addr, []byte(strings.Join(lines, "\n")), p, nil
+}
+
+//
+// 私有辅助
+///////////////////////////////////////////////////////////////////////////////
+
+// 附加变长数据。
+func appendBytes(b, data []byte) []byte {
+	b = binary.AppendUvarint(b, uint64(len(data)))
+	return append(b, data...)
+}
+
+// 简单读取器。
+// 出错后 bad 置位，后续读取返回零值。
+type reader struct {
+	buf []byte
+	bad bool
+}
+
+func (r *reader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.buf)
+	if n <= 0 {
+		r.bad = true
+		return 0
+	}
+	r.buf = r.buf[n:]
+	return v
+}
+
+// 读取条目数，不超过剩余字节数。
+func (r *reader) count() int {
+	n := r.uvarint()
+	if n > uint64(len(r.buf)) {
+		r.bad = true
+		return 0
+	}
+	return int(n)
+}
+
+func (r *reader) byte() byte {
+	if len(r.buf) < 1 {
+		r.bad = true
+		return 0
+	}
+	b := r.buf[0]
+	r.buf = r.buf[1:]
+	return b
+}
+
+func (r *reader) bytes() []byte {
+	n := r.uvarint()
+	if n > uint64(len(r.buf)) {
+		r.bad = true
+		return nil
+	}
+	b := append([]byte(nil), r.buf[:n]...)
+	r.buf = r.buf[n:]
+	return b
+}
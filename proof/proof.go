@@ -0,0 +1,151 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+// Package proof 签名消息，用于证明对账户地址的控制权。
+//
+// 消息哈希带有领域分隔前缀，避免签名被挪用为交易签名。
+// 验证时由证明中的公钥重新推导公钥地址（单签名经 paddr.Hash，
+// 多重签名经 paddr.MulHash），与账户地址比对后再验证各签名。
+// 签名算法为 Ed25519。
+package proof
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/binary"
+
+	"github.com/cxio/cbase"
+	"github.com/cxio/cbase/cerror"
+	"github.com/cxio/cbase/chash"
+	"github.com/cxio/cbase/paddr"
+)
+
+// 领域分隔前缀。
+const Domain = "CXIO Signed Message:\n"
+
+// 证明数据格式版本。
+const formatVersion = 1
+
+var (
+	// 证明数据格式错误。
+	ErrFormat = cerror.New(5001, "消息证明数据格式错误")
+
+	// 地址不符。
+	ErrAddress = cerror.New(5002, "证明的公钥与地址不符")
+
+	// 签名无效。
+	ErrSignature = cerror.New(5003, "消息签名无效")
+
+	// 无签名。
+	ErrNoSigner = cerror.New(5004, "证明中没有签名")
+
+	// 签名者序位重复。
+	ErrSignerIndex = cerror.New(5005, "签名者序位重复")
+)
+
+// Signer 单个签名。
+type Signer struct {
+	Index  byte   // 多重签名中的公钥序位，单签名时为0
+	PubKey []byte // 公钥
+	Sig    []byte // 签名
+}
+
+// Proof 地址控制权证明。
+// 多重签名时，Signers 为已签名的公钥（数量即为最少签名数），
+// Rest 为其余未签名的公钥地址（首字节为序位），规则同 paddr.MulHash。
+type Proof struct {
+	Multi   bool     // 是否为多重签名地址
+	Signers []Signer // 签名集
+	Rest    [][]byte // 未签名公钥地址集（仅多重签名）
+}
+
+// MessageHash 计算消息哈希。
+// 构成：领域前缀 + 消息长度（uvarint）+ 消息，执行256位哈希。
+func MessageHash(msg []byte) []byte {
+	n := binary.AppendUvarint(nil, uint64(len(msg)))
+	return chash.Sum256(1, cbase.ConcatBytes([]byte(Domain), n, msg))
+}
+
+// Sign 对消息签名。
+// index 为多重签名中的公钥序位，单签名时为0。
+func Sign(key ed25519.PrivateKey, index byte, msg []byte) Signer {
+	return Signer{
+		Index:  index,
+		PubKey: cbase.ConcatBytes(key.Public().(ed25519.PublicKey)),
+		Sig:    ed25519.Sign(key, MessageHash(msg)),
+	}
+}
+
+// SignMessage 单签名地址的消息签名。
+// 返回可直接验证的证明。
+func SignMessage(key ed25519.PrivateKey, msg []byte) *Proof {
+	return &Proof{Signers: []Signer{Sign(key, 0, msg)}}
+}
+
+// PKAddr 由证明推导公钥地址。
+func (p *Proof) PKAddr() (paddr.PKAddr, error) {
+	if len(p.Signers) == 0 {
+		return nil, ErrNoSigner
+	}
+	if !p.Multi {
+		if len(p.Signers) != 1 || len(p.Rest) > 0 {
+			return nil, ErrFormat
+		}
+		return paddr.Hash(p.Signers[0].PubKey, nil), nil
+	}
+	seen := make(map[byte]bool)
+	pks := make([][]byte, len(p.Signers))
+
+	for i, s := range p.Signers {
+		if seen[s.Index] {
+			return nil, ErrSignerIndex
+		}
+		seen[s.Index] = true
+		pks[i] = cbase.ConcatBytes([]byte{s.Index}, s.PubKey)
+	}
+	for _, r := range p.Rest {
+		if len(r) != paddr.HashSize+1 || seen[r[0]] {
+			return nil, ErrSignerIndex
+		}
+		seen[r[0]] = true
+	}
+	if len(seen) > paddr.MulSigMaxN {
+		return nil, paddr.ErrMSigSize
+	}
+	for i := 0; i < len(seen); i++ {
+		if !seen[byte(i)] {
+			return nil, paddr.ErrMSigIndex
+		}
+	}
+	return paddr.MulHash(pks, p.Rest)
+}
+
+// VerifyMessage 验证消息证明。
+// addr 为账户地址，需与证明推导出的公钥地址一致，且全部签名有效。
+func VerifyMessage(addr string, msg []byte, p *Proof) error {
+	pkh, _, err := paddr.Decode(addr)
+	if err != nil {
+		return err
+	}
+	return Verify(pkh, msg, p)
+}
+
+// Verify 以公钥地址验证消息证明。
+// 说明同 VerifyMessage。
+func Verify(pkh []byte, msg []byte, p *Proof) error {
+	got, err := p.PKAddr()
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(got, pkh) {
+		return ErrAddress
+	}
+	h := MessageHash(msg)
+
+	for _, s := range p.Signers {
+		if len(s.PubKey) != ed25519.PublicKeySize || !ed25519.Verify(s.PubKey, h, s.Sig) {
+			return ErrSignature.With(s.Index)
+		}
+	}
+	return nil
+}
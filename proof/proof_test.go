@@ -0,0 +1,81 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package proof_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"testing"
+
+	"github.com/cxio/cbase/paddr"
+	"github.com/cxio/cbase/proof"
+)
+
+// 由种子字节生成确定性密钥。
+func testKey(b byte) ed25519.PrivateKey {
+	return ed25519.NewKeyFromSeed(bytes.Repeat([]byte{b}, ed25519.SeedSize))
+}
+
+func TestSingle(t *testing.T) {
+	key := testKey(1)
+	addr := paddr.Encode(paddr.Hash(key.Public().(ed25519.PublicKey), nil), "cx")
+	msg := []byte("I own this address.\n-- signed")
+
+	p := proof.SignMessage(key, msg)
+	if err := proof.VerifyMessage(addr, msg, p); err != nil {
+		t.Fatalf("VerifyMessage: %v", err)
+	}
+	if err := proof.VerifyMessage(addr, []byte("other"), p); !errors.Is(err, proof.ErrSignature) {
+		t.Errorf("tampered message: got %v, want ErrSignature", err)
+	}
+	other := paddr.Encode(paddr.Hash([]byte("other"), nil), "cx")
+	if err := proof.VerifyMessage(other, msg, p); err != proof.ErrAddress {
+		t.Errorf("wrong address: got %v, want ErrAddress", err)
+	}
+	text := proof.Armor(addr, msg, p)
+
+	a, m, q, err := proof.Dearmor(text)
+	if err != nil || a != addr || !bytes.Equal(m, msg) {
+		t.Fatalf("Dearmor: got %q, %q, %v", a, m, err)
+	}
+	if err := proof.VerifyMessage(a, m, q); err != nil {
+		t.Errorf("VerifyMessage after Dearmor: %v", err)
+	}
+}
+
+func TestMulti(t *testing.T) {
+	k0, k1, k2 := testKey(1), testKey(2), testKey(3)
+	pub := func(k ed25519.PrivateKey) []byte { return k.Public().(ed25519.PublicKey) }
+
+	// 2/3：序位 0、2 签名，序位 1 未签名
+	pkh, err := paddr.MulHash(
+		[][]byte{append([]byte{0}, pub(k0)...), append([]byte{2}, pub(k2)...)},
+		[][]byte{append([]byte{1}, paddr.Hash(pub(k1), nil)...)},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("multisig ownership")
+	p := &proof.Proof{
+		Multi:   true,
+		Signers: []proof.Signer{proof.Sign(k0, 0, msg), proof.Sign(k2, 2, msg)},
+		Rest:    [][]byte{append([]byte{1}, paddr.Hash(pub(k1), nil)...)},
+	}
+	if err := proof.Verify(pkh, msg, p); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	q, err := proof.Parse(p.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := proof.Verify(pkh, msg, q); err != nil {
+		t.Errorf("Verify after Parse: %v", err)
+	}
+	// 签名数量不足时推导出的地址不同
+	p.Signers = p.Signers[:1]
+	if err := proof.Verify(pkh, msg, p); err == nil {
+		t.Error("Verify with missing signer: want error")
+	}
+}
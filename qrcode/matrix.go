@@ -0,0 +1,334 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package qrcode
+
+// 掩码惩罚权重。
+const (
+	penaltyN1 = 3
+	penaltyN2 = 3
+	penaltyN3 = 40
+	penaltyN4 = 10
+)
+
+// 构建二维码矩阵。
+// 放置功能图形和码字，选取惩罚分最低的掩码。
+func build(cws []byte, ver int, lv Level) *Code {
+	size := ver*4 + 17
+	c := &Code{
+		Version:  ver,
+		Level:    lv,
+		Size:     size,
+		modules:  grid(size),
+		function: grid(size),
+	}
+	c.drawFunctions()
+	c.drawCodewords(cws)
+
+	best, low := 0, -1
+
+	for m := 0; m < 8; m++ {
+		c.applyMask(m)
+		c.drawFormat(m)
+		if p := c.penalty(); low < 0 || p < low {
+			best, low = m, p
+		}
+		// 还原
+		c.applyMask(m)
+	}
+	c.Mask = best
+	c.applyMask(best)
+	c.drawFormat(best)
+
+	return c
+}
+
+// 创建方阵。
+func grid(size int) [][]bool {
+	g := make([][]bool, size)
+	for i := range g {
+		g[i] = make([]bool, size)
+	}
+	return g
+}
+
+// 设置功能模块。
+func (c *Code) setFunc(x, y int, dark bool) {
+	c.modules[y][x] = dark
+	c.function[y][x] = true
+}
+
+// 绘制全部功能图形。
+// 格式信息先以全零占位。
+func (c *Code) drawFunctions() {
+	for i := 0; i < c.Size; i++ {
+		c.setFunc(6, i, i%2 == 0)
+		c.setFunc(i, 6, i%2 == 0)
+	}
+	c.drawFinder(3, 3)
+	c.drawFinder(c.Size-4, 3)
+	c.drawFinder(3, c.Size-4)
+
+	pos := alignPositions(c.Version)
+	n := len(pos)
+
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			// 与定位图形重叠的跳过
+			if i == 0 && j == 0 || i == 0 && j == n-1 || i == n-1 && j == 0 {
+				continue
+			}
+			c.drawAlign(pos[i], pos[j])
+		}
+	}
+	c.drawFormat(0)
+	c.drawVersion()
+}
+
+// 绘制定位图形（含分隔符）。
+func (c *Code) drawFinder(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || yy < 0 || xx >= c.Size || yy >= c.Size {
+				continue
+			}
+			d := chebyshev(dx, dy)
+			c.setFunc(xx, yy, d != 2 && d != 4)
+		}
+	}
+}
+
+// 绘制校正图形。
+func (c *Code) drawAlign(x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			c.setFunc(x+dx, y+dy, chebyshev(dx, dy) != 1)
+		}
+	}
+}
+
+// 绘制格式信息（两份）。
+// 包含纠错等级和掩码，附带BCH校验。
+func (c *Code) drawFormat(mask int) {
+	bits := formatBits(c.Level, mask)
+
+	for i := 0; i <= 5; i++ {
+		c.setFunc(8, i, bit(bits, i))
+	}
+	c.setFunc(8, 7, bit(bits, 6))
+	c.setFunc(8, 8, bit(bits, 7))
+	c.setFunc(7, 8, bit(bits, 8))
+
+	for i := 9; i < 15; i++ {
+		c.setFunc(14-i, 8, bit(bits, i))
+	}
+	for i := 0; i < 8; i++ {
+		c.setFunc(c.Size-1-i, 8, bit(bits, i))
+	}
+	for i := 8; i < 15; i++ {
+		c.setFunc(8, c.Size-15+i, bit(bits, i))
+	}
+	// 暗模块
+	c.setFunc(8, c.Size-8, true)
+}
+
+// 绘制版本信息（版本7及以上）。
+func (c *Code) drawVersion() {
+	if c.Version < 7 {
+		return
+	}
+	rem := c.Version
+	for i := 0; i < 12; i++ {
+		rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+	}
+	bits := c.Version<<12 | rem
+
+	for i := 0; i < 18; i++ {
+		a, b := c.Size-11+i%3, i/3
+		c.setFunc(a, b, bit(bits, i))
+		c.setFunc(b, a, bit(bits, i))
+	}
+}
+
+// 按之字形路径放置码字。
+// 剩余位保持为浅色。
+func (c *Code) drawCodewords(cws []byte) {
+	i, n := 0, len(cws)*8
+
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		// 跳过垂直定时图形
+		if right == 6 {
+			right = 5
+		}
+		up := (right+1)&2 == 0
+
+		for v := 0; v < c.Size; v++ {
+			for j := 0; j < 2; j++ {
+				x, y := right-j, v
+				if up {
+					y = c.Size - 1 - v
+				}
+				if !c.function[y][x] && i < n {
+					c.modules[y][x] = cws[i>>3]>>uint(7-(i&7))&1 == 1
+					i++
+				}
+			}
+		}
+	}
+}
+
+// 应用掩码（异或，再次调用即还原）。
+func (c *Code) applyMask(m int) {
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if !c.function[y][x] && masked(m, x, y) {
+				c.modules[y][x] = !c.modules[y][x]
+			}
+		}
+	}
+}
+
+// 计算掩码惩罚分。
+func (c *Code) penalty() int {
+	p := 0
+	dark := 0
+
+	for i := 0; i < c.Size; i++ {
+		row := make([]bool, c.Size)
+		col := make([]bool, c.Size)
+		for j := 0; j < c.Size; j++ {
+			row[j] = c.modules[i][j]
+			col[j] = c.modules[j][i]
+			if row[j] {
+				dark++
+			}
+		}
+		p += linePenalty(row) + linePenalty(col)
+	}
+	// 2x2 同色块
+	for y := 0; y < c.Size-1; y++ {
+		for x := 0; x < c.Size-1; x++ {
+			v := c.modules[y][x]
+			if v == c.modules[y][x+1] && v == c.modules[y+1][x] && v == c.modules[y+1][x+1] {
+				p += penaltyN2
+			}
+		}
+	}
+	// 深浅比例偏离 50% 的程度
+	total := c.Size * c.Size
+	k := (abs(dark*20-total*10)+total-1)/total - 1
+	p += k * penaltyN4
+
+	return p
+}
+
+// 单行（列）的惩罚分。
+// 包含同色连续段和类定位图形两项。
+func linePenalty(line []bool) int {
+	p := 0
+	run := 1
+
+	for i := 1; i <= len(line); i++ {
+		if i < len(line) && line[i] == line[i-1] {
+			run++
+			continue
+		}
+		if run >= 5 {
+			p += penaltyN1 + run - 5
+		}
+		run = 1
+	}
+	// 1:1:3:1:1 图形，一侧带4个浅色模块
+	for i := 0; i+7 <= len(line); i++ {
+		if !(line[i] && !line[i+1] && line[i+2] && line[i+3] && line[i+4] && !line[i+5] && line[i+6]) {
+			continue
+		}
+		if lightRun(line, i-4, i) || lightRun(line, i+7, i+11) {
+			p += penaltyN3
+		}
+	}
+	return p
+}
+
+// 区间 [a, b) 是否全为浅色。
+// 超出边界的部分视为浅色。
+func lightRun(line []bool, a, b int) bool {
+	for i := a; i < b; i++ {
+		if i >= 0 && i < len(line) && line[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// 掩码条件。
+func masked(m, x, y int) bool {
+	switch m {
+	case 0:
+		return (x+y)%2 == 0
+	case 1:
+		return y%2 == 0
+	case 2:
+		return x%3 == 0
+	case 3:
+		return (x+y)%3 == 0
+	case 4:
+		return (x/3+y/2)%2 == 0
+	case 5:
+		return x*y%2+x*y%3 == 0
+	case 6:
+		return (x*y%2+x*y%3)%2 == 0
+	}
+	return ((x+y)%2+x*y%3)%2 == 0
+}
+
+// 格式信息15位编码。
+func formatBits(lv Level, mask int) int {
+	// 等级指示：L=01 M=00 Q=11 H=10
+	data := [4]int{1, 0, 3, 2}[lv]<<3 | mask
+	rem := data
+
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	return (data<<10 | rem) ^ 0x5412
+}
+
+// 校正图形中心坐标。
+// 横纵共用同一组坐标。
+func alignPositions(ver int) []int {
+	if ver == 1 {
+		return nil
+	}
+	n := ver/7 + 2
+	step := (ver*8 + n*3 + 5) / (n*4 - 4) * 2
+	out := make([]int, n)
+	out[0] = 6
+
+	for i, pos := n-1, ver*4+10; i >= 1; i, pos = i-1, pos-step {
+		out[i] = pos
+	}
+	return out
+}
+
+// 取整数第 i 位。
+func bit(v, i int) bool {
+	return (v>>uint(i))&1 != 0
+}
+
+// 切比雪夫距离。
+func chebyshev(dx, dy int) int {
+	dx, dy = abs(dx), abs(dy)
+	if dx > dy {
+		return dx
+	}
+	return dy
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,213 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+// Package qrcode 纯Go实现的二维码（QR Code）编码器。
+// 支持字节和字母数字两种模式、四种纠错等级，可输出PNG、SVG和终端字符画。
+// 主要用于账户地址和支付URI的展示。
+package qrcode
+
+import (
+	"strings"
+
+	"github.com/cxio/cbase/cerror"
+)
+
+// 纠错等级。
+type Level int
+
+const (
+	LevelL Level = iota // 约 7% 容错
+	LevelM              // 约 15% 容错
+	LevelQ              // 约 25% 容错
+	LevelH              // 约 30% 容错
+)
+
+// 编码模式。
+type Mode int
+
+const (
+	ModeAuto  Mode = iota // 自动选择
+	ModeAlnum             // 字母数字模式
+	ModeByte              // 字节模式
+)
+
+const (
+	// 最小版本号。
+	MinVersion = 1
+
+	// 最大版本号。
+	MaxVersion = 40
+)
+
+// 字母数字模式字符集。
+const alnumCharset = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+
+var (
+	// 数据过长。
+	ErrTooLong = cerror.New(3001, "数据过长，超出二维码容量")
+
+	// 数据与编码模式不符。
+	ErrMode = cerror.New(3002, "数据包含字母数字模式之外的字符")
+
+	// 纠错等级无效。
+	ErrLevel = cerror.New(3003, "无效的纠错等级")
+)
+
+// Code 已编码的二维码。
+// 模块矩阵按行存储，true 表示深色。
+type Code struct {
+	Version int   // 版本（1-40）
+	Level   Level // 纠错等级
+	Mask    int   // 掩码图案（0-7）
+	Size    int   // 边长（模块数）
+
+	modules  [][]bool // 模块矩阵
+	function [][]bool // 功能图形标记
+}
+
+// Encode 以自动模式编码文本。
+// 全部为字母数字字符集内的字符时采用字母数字模式，否则采用字节模式。
+// 注：
+// 大写字母数字模式的容量高于字节模式，大小写不敏感的地址宜先转大写。
+func Encode(text string, lv Level) (*Code, error) {
+	return EncodeMode([]byte(text), ModeAuto, lv)
+}
+
+// EncodeMode 以指定模式编码数据。
+// 版本自动选择为可容纳数据的最小版本。
+func EncodeMode(data []byte, mode Mode, lv Level) (*Code, error) {
+	if lv < LevelL || lv > LevelH {
+		return nil, ErrLevel
+	}
+	if mode == ModeAuto {
+		mode = ModeByte
+		if IsAlnum(string(data)) {
+			mode = ModeAlnum
+		}
+	}
+	if mode == ModeAlnum && !IsAlnum(string(data)) {
+		return nil, ErrMode
+	}
+	ver := 0
+
+	for v := MinVersion; v <= MaxVersion; v++ {
+		if segmentBits(mode, len(data), v) <= dataCodewords(v, lv)*8 {
+			ver = v
+			break
+		}
+	}
+	if ver == 0 {
+		return nil, ErrTooLong
+	}
+	cws := encodeData(data, mode, ver, lv)
+
+	return build(interleave(cws, ver, lv), ver, lv), nil
+}
+
+// IsAlnum 检查文本是否可用字母数字模式编码。
+func IsAlnum(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if strings.IndexByte(alnumCharset, s[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// At 获取模块颜色。
+// x 为列，y 为行，超出范围视为浅色。
+func (c *Code) At(x, y int) bool {
+	if x < 0 || y < 0 || x >= c.Size || y >= c.Size {
+		return false
+	}
+	return c.modules[y][x]
+}
+
+//
+// 私有辅助
+///////////////////////////////////////////////////////////////////////////////
+
+// 位缓存。
+type bitBuffer []byte
+
+// 添加 n 位值（高位在前）。
+func (b *bitBuffer) append(v, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*b = append(*b, byte((v>>uint(i))&1))
+	}
+}
+
+// 计数指示位长度。
+func countBits(mode Mode, ver int) int {
+	switch {
+	case mode == ModeAlnum && ver <= 9:
+		return 9
+	case mode == ModeAlnum && ver <= 26:
+		return 11
+	case mode == ModeAlnum:
+		return 13
+	case ver <= 9:
+		return 8
+	}
+	return 16
+}
+
+// 数据段所需的位数。
+// 超出计数指示位的表达范围时返回极大值。
+func segmentBits(mode Mode, n, ver int) int {
+	cb := countBits(mode, ver)
+	if n >= 1<<uint(cb) {
+		return int(^uint(0) >> 1)
+	}
+	if mode == ModeAlnum {
+		return 4 + cb + n/2*11 + n%2*6
+	}
+	return 4 + cb + n*8
+}
+
+// 编码数据码字。
+// 包含模式指示、计数指示、数据、终止符和填充字节。
+func encodeData(data []byte, mode Mode, ver int, lv Level) []byte {
+	var bb bitBuffer
+
+	if mode == ModeAlnum {
+		bb.append(0x2, 4)
+		bb.append(len(data), countBits(mode, ver))
+		i := 0
+		for ; i+1 < len(data); i += 2 {
+			a := strings.IndexByte(alnumCharset, data[i])
+			b := strings.IndexByte(alnumCharset, data[i+1])
+			bb.append(a*45+b, 11)
+		}
+		if i < len(data) {
+			bb.append(strings.IndexByte(alnumCharset, data[i]), 6)
+		}
+	} else {
+		bb.append(0x4, 4)
+		bb.append(len(data), countBits(mode, ver))
+		for _, b := range data {
+			bb.append(int(b), 8)
+		}
+	}
+	capbits := dataCodewords(ver, lv) * 8
+
+	// 终止符，最多4位
+	t := capbits - len(bb)
+	if t > 4 {
+		t = 4
+	}
+	bb.append(0, t)
+	// 字节对齐
+	bb.append(0, (8-len(bb)%8)%8)
+
+	// 交替填充
+	for pad := 0xEC; len(bb) < capbits; pad ^= 0xEC ^ 0x11 {
+		bb.append(pad, 8)
+	}
+	out := make([]byte, len(bb)/8)
+
+	for i, b := range bb {
+		out[i>>3] |= b << uint(7-(i&7))
+	}
+	return out
+}
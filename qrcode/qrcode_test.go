@@ -0,0 +1,137 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package qrcode
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestHelloWorldCodewords(t *testing.T) {
+	data := encodeData([]byte("HELLO WORLD"), ModeAlnum, 1, LevelM)
+	want := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	if !bytes.Equal(data, want) {
+		t.Fatalf("data codewords: got %v, want %v", data, want)
+	}
+	ecc := rsRemainder(data, rsDivisor(eccPerBlock[LevelM][1]))
+	wantEcc := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	if !bytes.Equal(ecc, wantEcc) {
+		t.Fatalf("ecc codewords: got %v, want %v", ecc, wantEcc)
+	}
+}
+
+func TestFormatBits(t *testing.T) {
+	tests := []struct {
+		lv   Level
+		mask int
+		want int
+	}{
+		{LevelL, 0, 0x77c4}, // 111011111000100
+		{LevelL, 4, 0x662f}, // 110011000101111
+		{LevelM, 0, 0x5412}, // 101010000010010
+	}
+	for _, x := range tests {
+		if got := formatBits(x.lv, x.mask); got != x.want {
+			t.Errorf("formatBits(%d, %d) = %015b, want %015b", x.lv, x.mask, got, x.want)
+		}
+	}
+}
+
+func TestCapacity(t *testing.T) {
+	tests := []struct {
+		ver  int
+		lv   Level
+		want int
+	}{
+		{1, LevelL, 19},
+		{1, LevelH, 9},
+		{10, LevelH, 122},
+		{40, LevelL, 2956},
+		{40, LevelH, 1276},
+	}
+	for _, x := range tests {
+		if got := dataCodewords(x.ver, x.lv); got != x.want {
+			t.Errorf("dataCodewords(%d, %d) = %d, want %d", x.ver, x.lv, got, x.want)
+		}
+	}
+}
+
+func TestAlignPositions(t *testing.T) {
+	tests := map[int][]int{
+		2:  {6, 18},
+		7:  {6, 22, 38},
+		32: {6, 34, 60, 86, 112, 138},
+		40: {6, 30, 58, 86, 114, 142, 170},
+	}
+	for ver, want := range tests {
+		got := alignPositions(ver)
+		if len(got) != len(want) {
+			t.Fatalf("alignPositions(%d) = %v, want %v", ver, got, want)
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Fatalf("alignPositions(%d) = %v, want %v", ver, got, want)
+			}
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		text string
+		lv   Level
+		ver  int
+	}{
+		{"HELLO WORLD", LevelQ, 1},
+		{"cx:3mJr7AoUXx2WqdyHg5bwPQ6zQNTL1bzDk", LevelM, 3},
+		{strings.Repeat("A", 4296), LevelL, 40},
+	}
+	for _, x := range tests {
+		c, err := Encode(x.text, x.lv)
+		if err != nil {
+			t.Fatalf("Encode(%q): %v", x.text, err)
+		}
+		if c.Version != x.ver || c.Size != x.ver*4+17 {
+			t.Errorf("Encode(%q): version %d size %d, want version %d", x.text, c.Version, c.Size, x.ver)
+		}
+		// 三个定位图形的中心为深色
+		if !c.At(3, 3) || !c.At(c.Size-4, 3) || !c.At(3, c.Size-4) {
+			t.Errorf("Encode(%q): finder patterns missing", x.text)
+		}
+	}
+	if _, err := Encode(strings.Repeat("a", 2954), LevelL); err != ErrTooLong {
+		t.Errorf("Encode oversized: got %v, want ErrTooLong", err)
+	}
+	if _, err := EncodeMode([]byte("abc"), ModeAlnum, LevelL); err != ErrMode {
+		t.Errorf("EncodeMode lowercase alnum: got %v, want ErrMode", err)
+	}
+}
+
+func TestRender(t *testing.T) {
+	c, err := Encode("HELLO WORLD", LevelM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+
+	if err := c.PNG(&buf, 4); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte("\x89PNG")) {
+		t.Error("PNG: bad signature")
+	}
+	buf.Reset()
+
+	if err := c.SVG(&buf, 4); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "<svg") {
+		t.Error("SVG: missing root element")
+	}
+	lines := strings.Split(strings.TrimRight(c.Text(false), "\n"), "\n")
+	if n := (c.Size + QuietZone*2 + 1) / 2; len(lines) != n {
+		t.Errorf("Text: %d lines, want %d", len(lines), n)
+	}
+}
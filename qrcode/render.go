@@ -0,0 +1,111 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package qrcode
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strings"
+)
+
+// 静区宽度（模块数）。
+// 规范要求至少4个模块。
+const QuietZone = 4
+
+// Image 生成灰度图像。
+// scale 为每模块像素数（至少为1），四周附带静区。
+func (c *Code) Image(scale int) image.Image {
+	if scale < 1 {
+		scale = 1
+	}
+	n := (c.Size + QuietZone*2) * scale
+	img := image.NewGray(image.Rect(0, 0, n, n))
+
+	for py := 0; py < n; py++ {
+		for px := 0; px < n; px++ {
+			v := color.Gray{Y: 0xff}
+			if c.At(px/scale-QuietZone, py/scale-QuietZone) {
+				v.Y = 0
+			}
+			img.SetGray(px, py, v)
+		}
+	}
+	return img
+}
+
+// PNG 输出PNG图像。
+// scale 含义同 Image。
+func (c *Code) PNG(w io.Writer, scale int) error {
+	return png.Encode(w, c.Image(scale))
+}
+
+// SVG 输出SVG矢量图。
+// 深色模块按行合并为路径，scale 为每模块的单位长度。
+func (c *Code) SVG(w io.Writer, scale int) error {
+	if scale < 1 {
+		scale = 1
+	}
+	n := c.Size + QuietZone*2
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" version="1.1" viewBox="0 0 %d %d" width="%d" height="%d" shape-rendering="crispEdges">`+"\n", n, n, n*scale, n*scale)
+	fmt.Fprintf(bw, `<rect width="100%%" height="100%%" fill="#ffffff"/>`+"\n")
+	bw.WriteString(`<path fill="#000000" d="`)
+
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; {
+			if !c.At(x, y) {
+				x++
+				continue
+			}
+			s := x
+			for x < c.Size && c.At(x, y) {
+				x++
+			}
+			fmt.Fprintf(bw, "M%d,%dh%dv1h-%dz", s+QuietZone, y+QuietZone, x-s, x-s)
+		}
+	}
+	bw.WriteString("\"/>\n</svg>\n")
+
+	return bw.Flush()
+}
+
+// Terminal 输出终端字符画。
+// 以半高块字符将两行模块合为一行，invert 为真时深浅反转（适合深色背景终端）。
+func (c *Code) Terminal(w io.Writer, invert bool) error {
+	_, err := io.WriteString(w, c.Text(invert))
+	return err
+}
+
+// Text 返回终端字符画文本。
+// 说明同 Terminal。
+func (c *Code) Text(invert bool) string {
+	var b strings.Builder
+	lo, hi := -QuietZone, c.Size+QuietZone
+
+	dark := func(x, y int) bool {
+		return c.At(x, y) != invert
+	}
+	for y := lo; y < hi; y += 2 {
+		for x := lo; x < hi; x++ {
+			top, bottom := dark(x, y), y+1 < hi && dark(x, y+1)
+			switch {
+			case top && bottom:
+				b.WriteRune('█')
+			case top:
+				b.WriteRune('▀')
+			case bottom:
+				b.WriteRune('▄')
+			default:
+				b.WriteByte(' ')
+			}
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
@@ -0,0 +1,129 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package qrcode
+
+// 每块纠错码字数。
+// 按纠错等级（L/M/Q/H）和版本（1-40）索引，首项占位。
+var eccPerBlock = [4][41]int{
+	{-1, 7, 10, 15, 20, 26, 18, 20, 24, 30, 18, 20, 24, 26, 30, 22, 24, 28, 30, 28, 28, 28, 28, 30, 30, 26, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	{-1, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26, 30, 22, 22, 24, 24, 28, 28, 26, 26, 26, 26, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28},
+	{-1, 13, 22, 18, 26, 18, 24, 18, 22, 20, 24, 28, 26, 24, 20, 30, 24, 28, 28, 26, 30, 28, 30, 30, 30, 30, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	{-1, 17, 28, 22, 16, 22, 28, 26, 26, 24, 28, 24, 28, 22, 24, 24, 30, 28, 28, 26, 28, 30, 24, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+}
+
+// 纠错块数量。
+// 索引方式同上。
+var eccBlocks = [4][41]int{
+	{-1, 1, 1, 1, 1, 1, 2, 2, 2, 2, 4, 4, 4, 4, 4, 6, 6, 6, 6, 7, 8, 8, 9, 9, 10, 12, 12, 12, 13, 14, 15, 16, 17, 18, 19, 19, 20, 21, 22, 24, 25},
+	{-1, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5, 5, 8, 9, 9, 10, 10, 11, 13, 14, 16, 17, 17, 18, 20, 21, 23, 25, 26, 28, 29, 31, 33, 35, 37, 38, 40, 43, 45, 47, 49},
+	{-1, 1, 1, 2, 2, 4, 4, 6, 6, 8, 8, 8, 10, 12, 16, 12, 17, 16, 18, 21, 20, 23, 23, 25, 27, 29, 34, 34, 35, 38, 40, 43, 45, 48, 51, 53, 56, 59, 62, 65, 68},
+	{-1, 1, 1, 2, 4, 4, 4, 5, 6, 8, 8, 11, 11, 16, 16, 18, 16, 19, 21, 25, 25, 25, 34, 30, 32, 35, 37, 40, 42, 45, 48, 51, 54, 57, 60, 63, 66, 70, 74, 77, 81},
+}
+
+// 版本可容纳的原始数据模块数。
+// 即扣除全部功能图形后的剩余模块，含纠错码部分。
+func rawModules(ver int) int {
+	n := (16*ver+128)*ver + 64
+
+	if ver >= 2 {
+		na := ver/7 + 2
+		n -= (25*na-10)*na - 55
+		if ver >= 7 {
+			n -= 36
+		}
+	}
+	return n
+}
+
+// 版本和纠错等级下的数据码字数。
+func dataCodewords(ver int, lv Level) int {
+	return rawModules(ver)/8 - eccPerBlock[lv][ver]*eccBlocks[lv][ver]
+}
+
+// 添加纠错码并交织。
+// data 为已填充完整的数据码字。
+// 返回最终需要放置的码字序列。
+func interleave(data []byte, ver int, lv Level) []byte {
+	nb := eccBlocks[lv][ver]
+	ecl := eccPerBlock[lv][ver]
+	raw := rawModules(ver) / 8
+	nshort := nb - raw%nb
+	slen := raw / nb
+	div := rsDivisor(ecl)
+
+	blocks := make([][]byte, nb)
+	k := 0
+
+	for i := 0; i < nb; i++ {
+		n := slen - ecl
+		if i >= nshort {
+			n++
+		}
+		dat := append([]byte(nil), data[k:k+n]...)
+		k += n
+		ecc := rsRemainder(dat, div)
+		// 短块占位，交织时跳过
+		if i < nshort {
+			dat = append(dat, 0)
+		}
+		blocks[i] = append(dat, ecc...)
+	}
+	out := make([]byte, 0, raw)
+
+	for i := range blocks[0] {
+		for j, b := range blocks {
+			if i != slen-ecl || j >= nshort {
+				out = append(out, b[i])
+			}
+		}
+	}
+	return out
+}
+
+// 构造Reed-Solomon生成多项式。
+// 系数由高到低，省略首项1。
+func rsDivisor(degree int) []byte {
+	out := make([]byte, degree)
+	out[degree-1] = 1
+	root := byte(1)
+
+	for i := 0; i < degree; i++ {
+		for j := range out {
+			out[j] = gfMul(out[j], root)
+			if j+1 < len(out) {
+				out[j] ^= out[j+1]
+			}
+		}
+		root = gfMul(root, 0x02)
+	}
+	return out
+}
+
+// 计算数据的Reed-Solomon余数（即纠错码字）。
+func rsRemainder(data, div []byte) []byte {
+	out := make([]byte, len(div))
+
+	for _, b := range data {
+		f := b ^ out[0]
+		copy(out, out[1:])
+		out[len(out)-1] = 0
+
+		for i, d := range div {
+			out[i] ^= gfMul(d, f)
+		}
+	}
+	return out
+}
+
+// GF(2^8) 乘法。
+// 模多项式为 0x11D。
+func gfMul(x, y byte) byte {
+	var z int
+
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11D)
+		z ^= int((y>>uint(i))&1) * int(x)
+	}
+	return byte(z)
+}
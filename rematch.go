@@ -0,0 +1,182 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package cbase
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+/*
+ * 正则式缓存
+ ******************************************************************************
+ */
+
+// 已编译正则式缓存。
+// 键为模式文本，值为 *regexp.Regexp。
+var reCache sync.Map
+
+// 获取编译后的正则式。
+// 同一模式仅编译一次，后续从缓存获取，可安全地并发调用。
+// 注：
+// 缓存不会清理，适用于数量有限的固定模式。
+func Regexp(pattern string) (*regexp.Regexp, error) {
+	if v, ok := reCache.Load(pattern); ok {
+		return v.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	v, _ := reCache.LoadOrStore(pattern, re)
+
+	return v.(*regexp.Regexp), nil
+}
+
+// 获取编译后的正则式。
+// 同 Regexp，但模式错误时抛出异常，适用于常量模式。
+func MustRegexp(pattern string) *regexp.Regexp {
+	re, err := Regexp(pattern)
+	if err != nil {
+		panic(err)
+	}
+	return re
+}
+
+// 以文本模式查找首个匹配。
+// 同 MatchOf，模式经由缓存编译。
+func MatchText[T Texter](target T, pattern string) ([]T, error) {
+	re, err := Regexp(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return MatchOf(target, re), nil
+}
+
+// 以文本模式查找全部匹配。
+// 同 MatchAllOf，模式经由缓存编译。
+func MatchAllText[T Texter](target T, pattern string) ([]T, error) {
+	re, err := Regexp(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return MatchAllOf(target, re), nil
+}
+
+// 以文本模式查找所有的匹配。
+// 同 MatchEveryOf，模式经由缓存编译。
+func MatchEveryText[T Texter](target T, pattern string) ([][]T, error) {
+	re, err := Regexp(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return MatchEveryOf(target, re), nil
+}
+
+/*
+ * 流式匹配
+ ******************************************************************************
+ */
+
+// 逐行匹配字节流。
+// 每找到一个匹配即调用 fn，传递行号（从1开始）和该匹配的子匹配集（首个为完整匹配）。
+// fn 返回 false 时停止读取。匹配不跨行，行尾的换行符不参与匹配。
+// 注意：
+// sub 中的数据在回调返回后会被复用，需保留时应复制。
+func MatchReader(r io.Reader, re *regexp.Regexp, fn func(line int, sub [][]byte) bool) error {
+	br := bufio.NewReader(r)
+	var buf []byte
+
+	for n := 1; ; n++ {
+		var err error
+		buf, err = readLine(br, buf[:0])
+
+		if len(buf) > 0 {
+			for _, sub := range re.FindAllSubmatch(buf, -1) {
+				if !fn(n, sub) {
+					return nil
+				}
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// 逐行匹配字符流。
+// 说明同 MatchReader，子匹配为字符串，无复用问题。
+func MatchRuneReader(r io.RuneReader, re *regexp.Regexp, fn func(line int, sub []string) bool) error {
+	var b strings.Builder
+
+	for n := 1; ; n++ {
+		b.Reset()
+		err := readRuneLine(r, &b)
+
+		if b.Len() > 0 {
+			for _, sub := range re.FindAllStringSubmatch(b.String(), -1) {
+				if !fn(n, sub) {
+					return nil
+				}
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// 读取一行到 buf。
+// 不限行长，去除行尾的 \n 或 \r\n。
+func readLine(br *bufio.Reader, buf []byte) ([]byte, error) {
+	for {
+		chunk, err := br.ReadSlice('\n')
+		buf = append(buf, chunk...)
+
+		if err != bufio.ErrBufferFull {
+			n := len(buf)
+			if n > 0 && buf[n-1] == '\n' {
+				n--
+				if n > 0 && buf[n-1] == '\r' {
+					n--
+				}
+			}
+			return buf[:n], err
+		}
+	}
+}
+
+// 读取一行字符到 b。
+// 去除行尾的 \n 或 \r\n。
+func readRuneLine(r io.RuneReader, b *strings.Builder) error {
+	cr := false
+
+	for {
+		c, _, err := r.ReadRune()
+		if err != nil {
+			if cr {
+				b.WriteByte('\r')
+			}
+			return err
+		}
+		if c == '\n' {
+			return nil
+		}
+		if cr {
+			b.WriteByte('\r')
+		}
+		if cr = c == '\r'; !cr {
+			b.WriteRune(c)
+		}
+	}
+}
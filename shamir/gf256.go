@@ -0,0 +1,59 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package shamir
+
+// GF(2^8) 运算
+// 模多项式 x^8+x^4+x^3+x+1（0x11B），生成元为 3。
+
+var (
+	gfExp [510]byte // 指数表（长度加倍，免去取模）
+	gfLog [256]byte // 对数表
+)
+
+func init() {
+	x := byte(1)
+
+	for i := 0; i < 255; i++ {
+		gfExp[i] = x
+		gfExp[i+255] = x
+		gfLog[x] = byte(i)
+		x ^= xtime(x)
+	}
+}
+
+// 乘以 x（即乘2）。
+func xtime(b byte) byte {
+	if b&0x80 != 0 {
+		return b<<1 ^ 0x1b
+	}
+	return b << 1
+}
+
+// 乘法。
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+int(gfLog[b])]
+}
+
+// 除法。
+// b 不可为零（调用者保证）。
+func gfDiv(a, b byte) byte {
+	if a == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+255-int(gfLog[b])]
+}
+
+// 多项式求值（Horner法）。
+// coef 由低次到高次排列。
+func gfEval(coef []byte, x byte) byte {
+	var y byte
+
+	for i := len(coef) - 1; i >= 0; i-- {
+		y = gfMul(y, x) ^ coef[i]
+	}
+	return y
+}
@@ -0,0 +1,211 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+// Package shamir 基于 GF(256) 的 Shamir 秘密分享，用于钱包种子的分片备份。
+//
+// 秘密被拆分为 N 个分片，任意 T 个即可恢复，少于 T 个则得不到任何信息。
+// 每个分片带有自身的校验码，可编码为 Base58 文本以便抄录保存。
+// 秘密在拆分前附加了摘要，恢复后会校验，可发现混入的错误分片。
+package shamir
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+
+	"github.com/cxio/cbase"
+	"github.com/cxio/cbase/base58"
+	"github.com/cxio/cbase/cerror"
+	"github.com/cxio/cbase/chash"
+)
+
+const (
+	// 分片数上限。
+	MaxShares = 255
+
+	// 分片格式版本。
+	shareVersion = 1
+
+	// 校验码及摘要长度。
+	checkSize = 4
+
+	// 分片头长度：版本、门限、序号、组标识。
+	headSize = 3 + 4
+)
+
+var (
+	// 分片参数错误。
+	ErrParams = cerror.New(6001, "分片参数错误：须 1 <= T <= N <= 255")
+
+	// 秘密为空。
+	ErrEmpty = cerror.New(6002, "秘密数据为空")
+
+	// 分片格式错误。
+	ErrFormat = cerror.New(6003, "分片格式错误")
+
+	// 分片校验错误。
+	ErrChecksum = cerror.New(6004, "分片校验错误")
+
+	// 分片不足。
+	ErrTooFew = cerror.New(6005, "分片数量不足")
+
+	// 分片不匹配。
+	ErrMismatch = cerror.New(6006, "分片不属于同一组")
+
+	// 分片重复。
+	ErrDuplicate = cerror.New(6007, "分片序号重复")
+
+	// 恢复结果校验失败。
+	ErrDigest = cerror.New(6008, "恢复的秘密校验失败，可能有错误的分片")
+)
+
+// 随机源。
+// 测试时可替换为确定性的来源。
+var random io.Reader = rand.Reader
+
+// Share 秘密分片。
+type Share struct {
+	Threshold byte   // 恢复所需分片数
+	Index     byte   // 分片序号（1-255），即多项式的自变量
+	Group     uint32 // 组标识，同次拆分的分片相同
+	Data      []byte // 分片数据
+}
+
+// Split 拆分秘密。
+// n 为分片总数，t 为恢复所需的分片数（门限）。
+func Split(secret []byte, n, t int) ([]*Share, error) {
+	if t < 1 || t > n || n > MaxShares {
+		return nil, ErrParams
+	}
+	if len(secret) == 0 {
+		return nil, ErrEmpty
+	}
+	var gid [4]byte
+	if _, err := io.ReadFull(random, gid[:]); err != nil {
+		return nil, err
+	}
+	// 秘密+摘要
+	data := cbase.ConcatBytes(secret, digest(secret))
+	shares := make([]*Share, n)
+
+	for i := range shares {
+		shares[i] = &Share{
+			Threshold: byte(t),
+			Index:     byte(i + 1),
+			Group:     binary.BigEndian.Uint32(gid[:]),
+			Data:      make([]byte, len(data)),
+		}
+	}
+	coef := make([]byte, t)
+
+	for j, b := range data {
+		// 常数项为秘密字节，其余系数随机
+		coef[0] = b
+		if _, err := io.ReadFull(random, coef[1:]); err != nil {
+			return nil, err
+		}
+		for _, s := range shares {
+			s.Data[j] = gfEval(coef, s.Index)
+		}
+	}
+	return shares, nil
+}
+
+// Combine 由分片恢复秘密。
+// 分片须来自同一次拆分，数量不少于门限，多余的分片被忽略。
+func Combine(shares []*Share) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, ErrTooFew
+	}
+	s0 := shares[0]
+	t := int(s0.Threshold)
+
+	if len(shares) < t {
+		return nil, ErrTooFew.With(len(shares), t)
+	}
+	seen := make(map[byte]bool, t)
+
+	for _, s := range shares {
+		if s.Group != s0.Group || s.Threshold != s0.Threshold || len(s.Data) != len(s0.Data) {
+			return nil, ErrMismatch
+		}
+		if s.Index == 0 || seen[s.Index] {
+			return nil, ErrDuplicate.With(s.Index)
+		}
+		seen[s.Index] = true
+	}
+	if len(s0.Data) <= checkSize {
+		return nil, ErrFormat
+	}
+	shares = shares[:t]
+	out := make([]byte, len(s0.Data))
+
+	// 拉格朗日插值求 x=0 处的值
+	for i, si := range shares {
+		// 基函数在 0 处的值：∏ xj/(xj-xi)，减法即异或
+		l := byte(1)
+		for j, sj := range shares {
+			if i != j {
+				l = gfMul(l, gfDiv(sj.Index, sj.Index^si.Index))
+			}
+		}
+		for k, y := range si.Data {
+			out[k] ^= gfMul(l, y)
+		}
+	}
+	n := len(out) - checkSize
+	secret, sum := out[:n], out[n:]
+
+	if !bytes.Equal(digest(secret), sum) {
+		return nil, ErrDigest
+	}
+	return secret, nil
+}
+
+// Bytes 分片序列化。
+// 格式：版本(1) 门限(1) 序号(1) 组标识(4) 数据 校验码(4)
+func (s *Share) Bytes() []byte {
+	b := []byte{shareVersion, s.Threshold, s.Index}
+	b = binary.BigEndian.AppendUint32(b, s.Group)
+	b = append(b, s.Data...)
+
+	return append(b, digest(b)...)
+}
+
+// String 分片的 Base58 文本。
+func (s *Share) String() string {
+	return base58.Encode(s.Bytes())
+}
+
+// ParseShare 解码分片数据。
+func ParseShare(b []byte) (*Share, error) {
+	if len(b) < headSize+checkSize+1 || b[0] != shareVersion {
+		return nil, ErrFormat
+	}
+	n := len(b) - checkSize
+
+	if !bytes.Equal(digest(b[:n]), b[n:]) {
+		return nil, ErrChecksum
+	}
+	return &Share{
+		Threshold: b[1],
+		Index:     b[2],
+		Group:     binary.BigEndian.Uint32(b[3:7]),
+		Data:      cbase.ConcatBytes(b[headSize:n]),
+	}, nil
+}
+
+// ParseText 解码分片的 Base58 文本。
+func ParseText(s string) (*Share, error) {
+	b := base58.Decode(s)
+	if len(b) == 0 {
+		return nil, ErrFormat
+	}
+	return ParseShare(b)
+}
+
+// 校验摘要。
+func digest(b []byte) []byte {
+	return chash.Sum256(1, b)[:checkSize]
+}
@@ -0,0 +1,76 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package shamir
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"testing"
+)
+
+func TestGF256(t *testing.T) {
+	for a := 1; a < 256; a++ {
+		for b := 1; b < 256; b++ {
+			p := gfMul(byte(a), byte(b))
+			if gfDiv(p, byte(b)) != byte(a) {
+				t.Fatalf("gfDiv(gfMul(%d, %d)) != %d", a, b, a)
+			}
+		}
+	}
+	// AES 中的已知乘积
+	if gfMul(0x57, 0x83) != 0xc1 {
+		t.Errorf("gfMul(0x57, 0x83) = %#x, want 0xc1", gfMul(0x57, 0x83))
+	}
+}
+
+func TestSplitCombine(t *testing.T) {
+	defer func(r io.Reader) { random = r }(random)
+	random = rand.New(rand.NewSource(1))
+	secret := []byte("correct horse battery staple seed")
+
+	shares, err := Split(secret, 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 任意 3 个分片都可恢复
+	for _, idx := range [][]int{{0, 1, 2}, {4, 2, 0}, {1, 3, 4}, {0, 1, 2, 3, 4}} {
+		var sub []*Share
+		for _, i := range idx {
+			sub = append(sub, shares[i])
+		}
+		got, err := Combine(sub)
+		if err != nil || !bytes.Equal(got, secret) {
+			t.Errorf("Combine%v: got %q, %v", idx, got, err)
+		}
+	}
+	if _, err := Combine(shares[:2]); err == nil {
+		t.Error("Combine with 2 shares: want error")
+	}
+	// 篡改的分片
+	bad := *shares[1]
+	bad.Data = append([]byte(nil), bad.Data...)
+	bad.Data[0] ^= 1
+	if _, err := Combine([]*Share{shares[0], &bad, shares[2]}); err != ErrDigest {
+		t.Errorf("Combine tampered: got %v, want ErrDigest", err)
+	}
+}
+
+func TestText(t *testing.T) {
+	shares, err := Split([]byte{1, 2, 3, 4}, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := shares[2].String()
+
+	got, err := ParseText(s)
+	if err != nil || got.Index != 3 || got.Threshold != 2 || !bytes.Equal(got.Data, shares[2].Data) {
+		t.Fatalf("ParseText: got %+v, %v", got, err)
+	}
+	b := shares[2].Bytes()
+	b[len(b)/2] ^= 0xff
+	if _, err := ParseShare(b); err != ErrChecksum {
+		t.Errorf("ParseShare corrupted: got %v, want ErrChecksum", err)
+	}
+}
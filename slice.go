@@ -0,0 +1,19 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package cbase
+
+// 串联字节序列。
+// 返回新分配的空间占用刚好的字节序列。
+func ConcatBytes(bs ...[]byte) []byte {
+	n := 0
+	for _, b := range bs {
+		n += len(b)
+	}
+	buf := make([]byte, 0, n)
+
+	for _, b := range bs {
+		buf = append(buf, b...)
+	}
+	return buf
+}
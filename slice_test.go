@@ -0,0 +1,20 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package cbase_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cxio/cbase"
+)
+
+func TestConcatBytes(t *testing.T) {
+	if got := cbase.ConcatBytes([]byte{1}, nil, []byte{2, 3}); !reflect.DeepEqual(got, []byte{1, 2, 3}) || cap(got) != 3 {
+		t.Errorf("ConcatBytes: got %v (cap %d)", got, cap(got))
+	}
+	if got := cbase.ConcatBytes(); got == nil || len(got) != 0 {
+		t.Errorf("ConcatBytes(): got %#v", got)
+	}
+}
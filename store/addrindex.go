@@ -0,0 +1,195 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package store
+
+import (
+	"sync"
+
+	"github.com/cxio/cbase/block"
+	"github.com/cxio/cbase/cerror"
+	"github.com/cxio/cbase/tx"
+)
+
+var (
+	// 区块不连续。
+	ErrIndexOrder = cerror.New(11005, "区块与地址索引链端不连续")
+
+	// 非链端区块。
+	ErrIndexTip = cerror.New(11006, "只能断开地址索引链端区块")
+)
+
+// OutRef 支付给地址的输出。
+type OutRef struct {
+	KeyID   tx.Vin  // 输出的脚本ID
+	TxID    tx.TxID // 所属交易
+	Height  int     // 区块高度
+	Amount  int64   // 币金金额，凭信输出为0
+	Credit  bool    // 是否为凭信输出
+	Spent   bool    // 是否已被花费
+	SpentBy tx.TxID // 花费交易
+}
+
+// 花费记录。
+type spend struct {
+	txid   tx.TxID
+	height int
+}
+
+// AddrIndex 地址索引（可选）。
+// 记录每个接收地址的币金和凭信输出，以及每个输入项的花费交易。
+// 随区块连接和断开更新，须按链的顺序进行。
+// 可安全地并发使用。
+type AddrIndex struct {
+	mu     sync.RWMutex
+	outs   map[string][]*OutRef // 地址到输出（按链顺序）
+	spends map[tx.Vin]spend     // 输入项到花费交易
+	chain  []block.Hash         // 已连接的区块
+	height int                  // 链端高度
+}
+
+// NewAddrIndex 创建空的地址索引。
+func NewAddrIndex() *AddrIndex {
+	return &AddrIndex{
+		outs:   make(map[string][]*OutRef),
+		spends: make(map[tx.Vin]spend),
+	}
+}
+
+// BuildAddrIndex 从存储中按高度顺序构建地址索引。
+func BuildAddrIndex(s *Store) (*AddrIndex, error) {
+	ix := NewAddrIndex()
+
+	for h := 0; h <= s.Tip(); h++ {
+		b, err := s.BlockAt(h)
+		if err != nil {
+			return nil, err
+		}
+		if err = ix.Connect(b); err != nil {
+			return nil, err
+		}
+	}
+	return ix, nil
+}
+
+// Connect 连接区块。
+// 首个区块的高度不限，之后的区块须紧接链端。
+func (ix *AddrIndex) Connect(b *block.Block) error {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	if n := len(ix.chain); n > 0 && (b.Height != ix.height+1 || b.Prev != ix.chain[n-1]) {
+		return ErrIndexOrder.With(b.Height)
+	}
+	for n, t := range b.Txs {
+		id := t.ID()
+
+		for _, in := range t.Body.Vins() {
+			ix.spends[in] = spend{id, b.Height}
+		}
+		for i, v := range t.Body.Vouts() {
+			addr := v.Receiver()
+			if addr == nil {
+				continue
+			}
+			r := &OutRef{KeyID: b.KeyID(n, i), TxID: id, Height: b.Height, Credit: v.Credit() != nil}
+
+			if c := v.Coin(); c != nil {
+				r.Amount = c.Amount
+			}
+			ix.outs[string(addr)] = append(ix.outs[string(addr)], r)
+		}
+	}
+	ix.chain = append(ix.chain, b.Hash())
+	ix.height = b.Height
+
+	return nil
+}
+
+// Disconnect 断开链端区块。
+func (ix *AddrIndex) Disconnect(b *block.Block) error {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	n := len(ix.chain)
+	if n == 0 || ix.chain[n-1] != b.Hash() {
+		return ErrIndexTip.With(b.Height)
+	}
+	// 本块的输出都在各地址列表的末尾
+	for _, t := range b.Txs {
+		for _, in := range t.Body.Vins() {
+			if sp, ok := ix.spends[in]; ok && sp.height == b.Height {
+				delete(ix.spends, in)
+			}
+		}
+		for _, v := range t.Body.Vouts() {
+			addr := v.Receiver()
+			if addr == nil {
+				continue
+			}
+			list := ix.outs[string(addr)]
+			if k := len(list) - 1; k >= 0 && list[k].Height == b.Height {
+				list = list[:k]
+			}
+			if len(list) == 0 {
+				delete(ix.outs, string(addr))
+			} else {
+				ix.outs[string(addr)] = list
+			}
+		}
+	}
+	ix.chain = ix.chain[:n-1]
+	ix.height = b.Height - 1
+
+	return nil
+}
+
+// Outputs 分页查询支付给地址的输出。
+// 按链的顺序，从第 from 项起最多 limit 项（limit<=0 表示不限）。
+// 同时返回该地址的输出总数。
+func (ix *AddrIndex) Outputs(addr tx.PKAddr, from, limit int) ([]OutRef, int) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	list := ix.outs[string(addr)]
+	total := len(list)
+
+	if from < 0 {
+		from = 0
+	}
+	if from >= total {
+		return nil, total
+	}
+	end := total
+	if limit > 0 && from+limit < end {
+		end = from + limit
+	}
+	out := make([]OutRef, 0, end-from)
+
+	for _, r := range list[from:end] {
+		ref := *r
+		if sp, ok := ix.spends[r.KeyID]; ok {
+			ref.Spent, ref.SpentBy = true, sp.txid
+		}
+		out = append(out, ref)
+	}
+	return out, total
+}
+
+// SpentBy 查询输入项的花费交易。
+func (ix *AddrIndex) SpentBy(in tx.Vin) (tx.TxID, bool) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	sp, ok := ix.spends[in]
+	return sp.txid, ok
+}
+
+// Height 链端高度。
+// 尚未连接区块时ok为false。
+func (ix *AddrIndex) Height() (int, bool) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	return ix.height, len(ix.chain) > 0
+}
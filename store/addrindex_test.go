@@ -0,0 +1,74 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package store
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/cxio/cbase"
+	"github.com/cxio/cbase/block"
+	"github.com/cxio/cbase/tx"
+)
+
+func TestAddrIndex(t *testing.T) {
+	chain := testChain(5, 2)
+	pkh := tx.PKAddr(bytes.Repeat([]byte{3}, 20))
+
+	// 第5块花费高度0的首个输出
+	var in tx.Vin
+	copy(in[:], cbase.KeyID(0, 0, 0))
+	spender := tx.New(tx.Header{Version: tx.Version, Minter: pkh}, tx.NewBody([]tx.Vin{in}, []tx.Vout{
+		tx.NewCreditOut(&tx.Credit{Receiver: pkh}),
+	}))
+	last := &block.Block{Height: 5, Prev: chain[4].Hash(), Txs: []*tx.Tx{spender}}
+	chain = append(chain, last)
+
+	s, err := Open(t.TempDir(), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	putAll(t, s, chain)
+
+	// 按脚本ID解析输入项
+	if o, err := s.Output(in); err != nil || o.Vout.Coin().Amount != 1 {
+		t.Errorf("Output(%s): %v", in, err)
+	}
+	ix, err := BuildAddrIndex(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, total := ix.Outputs(pkh, 0, 4)
+	if total != 11 || len(page) != 4 {
+		t.Fatalf("page 1: %d of %d", len(page), total)
+	}
+	if page[0].KeyID != in || !page[0].Spent || page[0].SpentBy != spender.ID() || page[0].Amount != 1 {
+		t.Errorf("first output: %+v", page[0])
+	}
+	page, _ = ix.Outputs(pkh, 8, 4)
+	if len(page) != 3 || !page[2].Credit || page[2].Height != 5 {
+		t.Errorf("last page: %+v", page)
+	}
+	if id, ok := ix.SpentBy(in); !ok || id != spender.ID() {
+		t.Error("SpentBy")
+	}
+	// 断开后花费和输出都撤销
+	if err := ix.Disconnect(chain[4]); !errors.Is(err, ErrIndexTip) {
+		t.Errorf("disconnect non-tip: got %v", err)
+	}
+	if err := ix.Disconnect(last); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := ix.SpentBy(in); ok {
+		t.Error("spend not removed")
+	}
+	if _, total := ix.Outputs(pkh, 0, 0); total != 10 {
+		t.Errorf("after disconnect: %d outputs", total)
+	}
+	if err := ix.Connect(chain[2]); !errors.Is(err, ErrIndexOrder) {
+		t.Errorf("out of order connect: got %v", err)
+	}
+}
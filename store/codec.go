@@ -0,0 +1,85 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package store
+
+import (
+	"encoding/binary"
+
+	"github.com/cxio/cbase/block"
+	"github.com/cxio/cbase/tx"
+)
+
+// 交易在记录数据中的位置。
+type span struct {
+	id   tx.TxID
+	off  uint32
+	size uint32
+}
+
+// 编码区块记录的数据部分。
+// 格式：高度（4）| 前一区块哈希（32）| 时间戳（8）| uvarint 交易数 | 交易*
+// 每笔交易为 uvarint 长度加紧凑格式数据。
+// 交易无法紧凑编码（如未知版本）时返回其错误。
+func encodeBlock(b *block.Block) ([]byte, []span, error) {
+	buf := make([]byte, 0, 64)
+
+	buf = binary.BigEndian.AppendUint32(buf, uint32(b.Height))
+	buf = append(buf, b.Prev[:]...)
+	buf = binary.BigEndian.AppendUint64(buf, uint64(b.Time))
+	buf = binary.AppendUvarint(buf, uint64(len(b.Txs)))
+
+	spans := make([]span, len(b.Txs))
+
+	for i, t := range b.Txs {
+		data, err := t.Compact()
+		if err != nil {
+			return nil, nil, err
+		}
+		buf = binary.AppendUvarint(buf, uint64(len(data)))
+		spans[i] = span{t.ID(), uint32(len(buf)), uint32(len(data))}
+		buf = append(buf, data...)
+	}
+	return buf, spans, nil
+}
+
+// 解码区块记录的数据部分。
+func decodeBlock(p []byte) (*block.Block, []span, error) {
+	const head = 4 + block.HashSize + 8
+
+	if len(p) < head {
+		return nil, nil, ErrCorrupt
+	}
+	b := &block.Block{Height: int(binary.BigEndian.Uint32(p))}
+	copy(b.Prev[:], p[4:])
+	b.Time = int64(binary.BigEndian.Uint64(p[4+block.HashSize:]))
+
+	pos := head
+	n, k := binary.Uvarint(p[pos:])
+	// 每笔交易至少占用2字节
+	if k <= 0 || n > uint64(len(p)-pos)/2 {
+		return nil, nil, ErrCorrupt
+	}
+	pos += k
+	b.Txs = make([]*tx.Tx, n)
+	spans := make([]span, n)
+
+	for i := range b.Txs {
+		size, k := binary.Uvarint(p[pos:])
+		if k <= 0 || size > uint64(len(p)-pos-k) {
+			return nil, nil, ErrCorrupt
+		}
+		pos += k
+		t, err := tx.DecodeCompact(p[pos : pos+int(size)])
+		if err != nil {
+			return nil, nil, err
+		}
+		b.Txs[i] = t
+		spans[i] = span{t.ID(), uint32(pos), uint32(size)}
+		pos += int(size)
+	}
+	if pos != len(p) {
+		return nil, nil, ErrCorrupt
+	}
+	return b, spans, nil
+}
@@ -0,0 +1,138 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package store
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/cxio/cbase/block"
+	"github.com/cxio/cbase/tx"
+)
+
+// 索引条目类型与长度。
+// 区块条目：类型 | 哈希（32）| 高度（4）| 文件（4）| 偏移（8）| 长度（4）| 交易数（4）
+// 交易条目：类型 | 交易ID（32）| 文件（4）| 偏移（8）| 长度（4）
+// 每个区块条目之后紧随其全部交易条目，构成一组。
+const (
+	entryBlock = 'B'
+	entryTx    = 'T'
+
+	blockEntrySize = 1 + block.HashSize + 4 + 4 + 8 + 4 + 4
+	txEntrySize    = 1 + tx.TxIDSize + 4 + 8 + 4
+)
+
+// 载入索引文件。
+// 末尾残缺的条目组被截除。返回最后一个已索引区块之后的数据位置。
+func (s *Store) loadIndex() (uint32, int64, error) {
+	data, err := io.ReadAll(s.index)
+	if err != nil {
+		return 0, 0, err
+	}
+	var file uint32
+	var end int64
+	pos := 0
+
+	for len(data)-pos >= blockEntrySize && data[pos] == entryBlock {
+		e := data[pos+1:]
+		var h block.Hash
+		copy(h[:], e)
+		e = e[block.HashSize:]
+
+		height := int(binary.BigEndian.Uint32(e))
+		l := getLoc(e[4:])
+		ntx := int(binary.BigEndian.Uint32(e[20:]))
+
+		next := pos + blockEntrySize + ntx*txEntrySize
+		if next > len(data) || !s.loadTxs(h, data[pos+blockEntrySize:next]) {
+			break
+		}
+		s.apply(h, height, l)
+		file, end = l.file, l.off+int64(l.size)
+		pos = next
+	}
+	if pos < len(data) {
+		if err = s.index.Truncate(int64(pos)); err != nil {
+			return 0, 0, err
+		}
+	}
+	s.idxEnd = int64(pos)
+
+	return file, end, nil
+}
+
+// 载入一组交易条目。
+// 条目类型不符时返回false，不做任何改动。
+func (s *Store) loadTxs(h block.Hash, data []byte) bool {
+	for i := 0; i < len(data); i += txEntrySize {
+		if data[i] != entryTx {
+			return false
+		}
+	}
+	for i := 0; i < len(data); i += txEntrySize {
+		var id tx.TxID
+		copy(id[:], data[i+1:])
+		s.txs[id] = txLoc{getLoc(data[i+1+tx.TxIDSize:]), h}
+	}
+	return true
+}
+
+// 登记区块到索引文件和内存索引。
+// l 为记录在数据文件中的位置，spans 为各交易在记录数据部分中的位置。
+func (s *Store) record(h block.Hash, height int, l loc, spans []span) error {
+	buf := make([]byte, 0, blockEntrySize+len(spans)*txEntrySize)
+
+	buf = append(buf, entryBlock)
+	buf = append(buf, h[:]...)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(height))
+	buf = putLoc(buf, l)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(spans)))
+
+	tls := make([]loc, len(spans))
+	for i, sp := range spans {
+		tls[i] = loc{l.file, l.off + recordHeaderSize + int64(sp.off), sp.size}
+		buf = append(buf, entryTx)
+		buf = append(buf, sp.id[:]...)
+		buf = putLoc(buf, tls[i])
+	}
+	if _, err := s.index.WriteAt(buf, s.idxEnd); err != nil {
+		return err
+	}
+	if err := s.index.Sync(); err != nil {
+		return err
+	}
+	s.idxEnd += int64(len(buf))
+
+	s.apply(h, height, l)
+	for i, sp := range spans {
+		s.txs[sp.id] = txLoc{tls[i], h}
+	}
+	return nil
+}
+
+// 登记区块到内存索引。
+func (s *Store) apply(h block.Hash, height int, l loc) {
+	s.blocks[h] = blockLoc{l, height}
+	s.height[height] = h
+
+	if height > s.tip {
+		s.tip = height
+	}
+}
+
+// 编码位置：文件（4）| 偏移（8）| 长度（4）。
+func putLoc(buf []byte, l loc) []byte {
+	buf = binary.BigEndian.AppendUint32(buf, l.file)
+	buf = binary.BigEndian.AppendUint64(buf, uint64(l.off))
+	return binary.BigEndian.AppendUint32(buf, l.size)
+}
+
+// 解码位置。
+func getLoc(b []byte) loc {
+	return loc{
+		file: binary.BigEndian.Uint32(b),
+		off:  int64(binary.BigEndian.Uint64(b[4:])),
+		size: binary.BigEndian.Uint32(b[12:]),
+	}
+}
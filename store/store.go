@@ -0,0 +1,516 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+// Package store 区块的平面文件存储。
+//
+// 区块依次追加到数据文件（blk00000.dat, blk00001.dat...），
+// 文件超过限定大小时轮换到下一个。每个区块为一条记录：
+//
+//	魔数（4） | 数据长度（4） | CRC32（4） | 数据
+//
+// 数据为区块头字段和紧凑格式（tx.Compact）的交易集。
+// 索引文件（index.dat）记录区块哈希、高度及交易ID到文件位置的映射，
+// 在区块数据同步到磁盘之后追加。
+//
+// 打开时载入索引，截除残缺的索引条目，再从最后一个已索引的区块之后扫描数据文件：
+// 完整有效的记录补入索引，残缺或损坏的尾部被截除。因此写入中途崩溃不影响已存储的区块。
+// 校验通过却无法解码的记录不是写入中断所致，此时打开失败（ErrCorrupt），不截除数据。
+// 为此存入的区块须通过交易检查（tx.Check）。
+//
+// 地址索引（AddrIndex）为可选组件，保存在内存中，可由存储重建。
+package store
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/cxio/cbase"
+	"github.com/cxio/cbase/block"
+	"github.com/cxio/cbase/cerror"
+	"github.com/cxio/cbase/tx"
+)
+
+// 默认数据文件大小上限。
+const DefaultMaxFileSize = 128 << 20
+
+// 记录头长度。
+const recordHeaderSize = 12
+
+// 索引文件名。
+const indexName = "index.dat"
+
+// 记录魔数。
+var magic = [4]byte{'c', 'x', 'b', 'k'}
+
+// CRC32 校验表。
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+var (
+	// 未找到。
+	ErrNotFound = cerror.New(11001, "存储中未找到")
+
+	// 区块已存在。
+	ErrExists = cerror.New(11002, "区块已存储")
+
+	// 数据损坏。
+	ErrCorrupt = cerror.New(11003, "存储数据已损坏")
+
+	// 存储已关闭。
+	ErrClosed = cerror.New(11004, "存储已关闭")
+)
+
+// Options 存储配置。
+type Options struct {
+	MaxFileSize int64 // 单个数据文件的大小上限，0 为默认值
+}
+
+// 文件中的位置。
+type loc struct {
+	file uint32 // 文件序号
+	off  int64  // 偏移
+	size uint32 // 长度
+}
+
+// 区块位置。
+type blockLoc struct {
+	loc
+	height int
+}
+
+// 交易位置。
+type txLoc struct {
+	loc
+	block block.Hash
+}
+
+// Store 区块存储。
+// 可安全地并发使用。
+type Store struct {
+	mu     sync.RWMutex
+	dir    string
+	opt    Options
+	index  *os.File            // 索引文件
+	idxEnd int64               // 索引文件大小
+	cur    *os.File            // 当前数据文件（追加）
+	curN   uint32              // 当前数据文件序号
+	curEnd int64               // 当前数据文件大小
+	fmu    sync.Mutex          // 文件缓存锁（读取时在 mu 的读锁下填充）
+	files  map[uint32]*os.File // 读取用的文件
+	blocks map[block.Hash]blockLoc
+	height map[int]block.Hash
+	txs    map[tx.TxID]txLoc
+	tip    int // 最高的区块高度
+	closed bool
+}
+
+// Open 打开或创建存储目录。
+// 会载入索引并恢复写入中途崩溃留下的残缺数据。
+func Open(dir string, opt Options) (*Store, error) {
+	if opt.MaxFileSize <= 0 {
+		opt.MaxFileSize = DefaultMaxFileSize
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	s := &Store{
+		dir:    dir,
+		opt:    opt,
+		files:  make(map[uint32]*os.File),
+		blocks: make(map[block.Hash]blockLoc),
+		height: make(map[int]block.Hash),
+		txs:    make(map[tx.TxID]txLoc),
+		tip:    -1,
+	}
+	if err := s.open(); err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Close 关闭存储。
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	var err error
+
+	for _, f := range s.files {
+		if e := f.Close(); err == nil {
+			err = e
+		}
+	}
+	for _, f := range []*os.File{s.cur, s.index} {
+		if f == nil {
+			continue
+		}
+		if e := f.Close(); err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// Tip 已存储区块的最高高度。
+// 无区块时为-1。
+func (s *Store) Tip() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.tip
+}
+
+// Has 区块是否已存储。
+func (s *Store) Has(h block.Hash) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, ok := s.blocks[h]
+	return ok
+}
+
+// Put 存储区块。
+// 区块中的交易须通过检查（tx.Check），否则返回首个错误。
+// 区块数据同步到磁盘后才更新索引。
+// 同一高度的后存区块取代先前的高度映射（如链重组）。
+func (s *Store) Put(b *block.Block) (block.Hash, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	h := b.Hash()
+	if s.closed {
+		return h, ErrClosed
+	}
+	if _, ok := s.blocks[h]; ok {
+		return h, ErrExists.With(h)
+	}
+	for _, t := range b.Txs {
+		if errs := t.Check(); errs != nil {
+			return h, errs[0]
+		}
+	}
+	payload, spans, err := encodeBlock(b)
+	if err != nil {
+		return h, err
+	}
+	rec := makeRecord(payload)
+
+	if s.curEnd > 0 && s.curEnd+int64(len(rec)) > s.opt.MaxFileSize {
+		if err = s.rotate(); err != nil {
+			return h, err
+		}
+	}
+	off := s.curEnd
+
+	if _, err = s.cur.WriteAt(rec, off); err != nil {
+		return h, err
+	}
+	if err = s.cur.Sync(); err != nil {
+		return h, err
+	}
+	s.curEnd += int64(len(rec))
+
+	return h, s.record(h, b.Height, loc{s.curN, off, uint32(len(rec))}, spans)
+}
+
+// Block 按哈希读取区块。
+func (s *Store) Block(h block.Hash) (*block.Block, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	bl, ok := s.blocks[h]
+	if !ok {
+		return nil, ErrNotFound.With(h)
+	}
+	return s.readBlock(bl.loc)
+}
+
+// BlockAt 按高度读取区块。
+func (s *Store) BlockAt(height int) (*block.Block, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	h, ok := s.height[height]
+	if !ok {
+		return nil, ErrNotFound.With(height)
+	}
+	return s.readBlock(s.blocks[h].loc)
+}
+
+// Tx 按交易ID读取交易，同时返回所在区块的哈希。
+// 只读取该交易的数据，不校验整条记录。
+func (s *Store) Tx(id tx.TxID) (*tx.Tx, block.Hash, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tl, ok := s.txs[id]
+	if !ok {
+		return nil, block.Hash{}, ErrNotFound.With(id)
+	}
+	data, err := s.read(tl.loc)
+	if err != nil {
+		return nil, tl.block, err
+	}
+	t, err := tx.DecodeCompact(data)
+	if err != nil {
+		return nil, tl.block, ErrCorrupt.With(err)
+	}
+	return t, tl.block, nil
+}
+
+// Output 按脚本ID读取输出。
+// 由脚本ID中的高度定位区块，再在区块中查找。
+func (s *Store) Output(in tx.Vin) (block.Output, error) {
+	h, _, _ := cbase.SplitKeyID(in[:])
+
+	b, err := s.BlockAt(h)
+	if err != nil {
+		return block.Output{}, err
+	}
+	o, ok := b.Lookup(in)
+	if !ok {
+		return o, ErrNotFound.With(in)
+	}
+	return o, nil
+}
+
+//
+// 私有辅助
+///////////////////////////////////////////////////////////////////////////////
+
+// 数据文件路径。
+func (s *Store) dataPath(n uint32) string {
+	return filepath.Join(s.dir, fmt.Sprintf("blk%05d.dat", n))
+}
+
+// 现有数据文件的序号集（升序）。
+func (s *Store) dataFiles() ([]uint32, error) {
+	names, err := filepath.Glob(filepath.Join(s.dir, "blk*.dat"))
+	if err != nil {
+		return nil, err
+	}
+	var out []uint32
+
+	for _, name := range names {
+		var n uint32
+		if _, err := fmt.Sscanf(filepath.Base(name), "blk%05d.dat", &n); err == nil {
+			out = append(out, n)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+
+	return out, nil
+}
+
+// 打开文件、载入索引并恢复。
+func (s *Store) open() error {
+	var err error
+
+	s.index, err = os.OpenFile(filepath.Join(s.dir, indexName), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	// 扫描起点：最后一个已索引区块之后
+	file, off, err := s.loadIndex()
+	if err != nil {
+		return err
+	}
+	ns, err := s.dataFiles()
+	if err != nil {
+		return err
+	}
+	for _, n := range ns {
+		if n < file {
+			continue
+		}
+		if n > file {
+			off = 0
+		}
+		if err = s.recover(n, off); err != nil {
+			return err
+		}
+	}
+	if len(ns) > 0 && ns[len(ns)-1] > s.curN {
+		s.curN = ns[len(ns)-1]
+	}
+	return s.openCurrent()
+}
+
+// 打开当前数据文件用于追加。
+func (s *Store) openCurrent() error {
+	f, err := os.OpenFile(s.dataPath(s.curN), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	s.cur, s.curEnd = f, fi.Size()
+
+	return nil
+}
+
+// 轮换到下一个数据文件。
+func (s *Store) rotate() error {
+	if err := s.cur.Close(); err != nil {
+		return err
+	}
+	s.curN++
+	return s.openCurrent()
+}
+
+// 获取读取用的文件。
+// 调用者持有 mu 的读锁即可，缓存由 fmu 保护。
+func (s *Store) file(n uint32) (*os.File, error) {
+	if n == s.curN && s.cur != nil {
+		return s.cur, nil
+	}
+	s.fmu.Lock()
+	defer s.fmu.Unlock()
+
+	if f, ok := s.files[n]; ok {
+		return f, nil
+	}
+	f, err := os.Open(s.dataPath(n))
+	if err != nil {
+		return nil, err
+	}
+	s.files[n] = f
+
+	return f, nil
+}
+
+// 读取数据。
+func (s *Store) read(l loc) ([]byte, error) {
+	if s.closed {
+		return nil, ErrClosed
+	}
+	f, err := s.file(l.file)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, l.size)
+
+	if _, err := f.ReadAt(buf, l.off); err != nil {
+		return nil, ErrCorrupt.With(err)
+	}
+	return buf, nil
+}
+
+// 读取并校验区块记录。
+func (s *Store) readBlock(l loc) (*block.Block, error) {
+	rec, err := s.read(l)
+	if err != nil {
+		return nil, err
+	}
+	payload, ok := checkRecord(rec)
+	if !ok {
+		return nil, ErrCorrupt.With(l.file, l.off)
+	}
+	b, _, err := decodeBlock(payload)
+	if err != nil {
+		return nil, ErrCorrupt.With(err)
+	}
+	return b, nil
+}
+
+// 从数据文件的 off 处扫描记录。
+// 有效记录补入索引，遇到残缺或校验失败的记录时截除其后的全部数据。
+// 校验通过但无法解码的记录返回 ErrCorrupt，不截除。
+func (s *Store) recover(n uint32, off int64) error {
+	f, err := os.OpenFile(s.dataPath(n), os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	for off < fi.Size() {
+		rec, ok := readRecord(f, off, fi.Size())
+		if !ok {
+			return f.Truncate(off)
+		}
+		b, spans, err := decodeBlock(rec[recordHeaderSize:])
+		if err != nil {
+			return ErrCorrupt.With(n, off, err)
+		}
+		if h := b.Hash(); !s.hasBlock(h) {
+			if err = s.record(h, b.Height, loc{n, off, uint32(len(rec))}, spans); err != nil {
+				return err
+			}
+		}
+		off += int64(len(rec))
+	}
+	return nil
+}
+
+// 是否已索引区块。
+func (s *Store) hasBlock(h block.Hash) bool {
+	_, ok := s.blocks[h]
+	return ok
+}
+
+// 读取 off 处的完整记录。
+// 记录残缺或校验失败时ok为false。
+func readRecord(f *os.File, off, end int64) ([]byte, bool) {
+	var head [recordHeaderSize]byte
+
+	if end-off < recordHeaderSize {
+		return nil, false
+	}
+	if _, err := f.ReadAt(head[:], off); err != nil {
+		return nil, false
+	}
+	n := int64(binary.BigEndian.Uint32(head[4:]))
+	if end-off-recordHeaderSize < n {
+		return nil, false
+	}
+	rec := make([]byte, recordHeaderSize+n)
+
+	if _, err := f.ReadAt(rec, off); err != nil {
+		return nil, false
+	}
+	if _, ok := checkRecord(rec); !ok {
+		return nil, false
+	}
+	return rec, true
+}
+
+// 构造记录。
+func makeRecord(payload []byte) []byte {
+	rec := make([]byte, recordHeaderSize, recordHeaderSize+len(payload))
+
+	copy(rec, magic[:])
+	binary.BigEndian.PutUint32(rec[4:], uint32(len(payload)))
+	binary.BigEndian.PutUint32(rec[8:], crc32.Checksum(payload, crcTable))
+
+	return append(rec, payload...)
+}
+
+// 校验记录，返回数据部分。
+func checkRecord(rec []byte) ([]byte, bool) {
+	if len(rec) < recordHeaderSize || !bytes.Equal(rec[:4], magic[:]) {
+		return nil, false
+	}
+	payload := rec[recordHeaderSize:]
+
+	if int64(binary.BigEndian.Uint32(rec[4:])) != int64(len(payload)) {
+		return nil, false
+	}
+	return payload, crc32.Checksum(payload, crcTable) == binary.BigEndian.Uint32(rec[8:])
+}
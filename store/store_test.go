@@ -0,0 +1,304 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package store
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/cxio/cbase/block"
+	"github.com/cxio/cbase/tx"
+)
+
+// 构造测试链，每块含 n 笔交易。
+func testChain(count, n int) []*block.Block {
+	var prev block.Hash
+	pkh := bytes.Repeat([]byte{3}, 20)
+	out := make([]*block.Block, count)
+
+	for h := range out {
+		b := &block.Block{Height: h, Prev: prev, Time: int64(h) * 1000}
+		for i := 0; i < n; i++ {
+			body := tx.NewBody(nil, []tx.Vout{
+				tx.NewCoinOut(&tx.Coin{Receiver: pkh, Amount: int64(h*100 + i + 1)}),
+			})
+			b.Txs = append(b.Txs, tx.New(tx.Header{Version: tx.Version, Timestamp: int64(h), Minter: pkh}, body))
+		}
+		out[h], prev = b, b.Hash()
+	}
+	return out
+}
+
+// 存入全部区块。
+func putAll(t *testing.T, s *Store, chain []*block.Block) {
+	for _, b := range chain {
+		if _, err := s.Put(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// 检查存储中的全部区块和交易。
+func checkAll(t *testing.T, s *Store, chain []*block.Block) {
+	t.Helper()
+
+	for _, want := range chain {
+		got, err := s.BlockAt(want.Height)
+		if err != nil {
+			t.Fatalf("BlockAt(%d): %v", want.Height, err)
+		}
+		if got.Hash() != want.Hash() {
+			t.Errorf("BlockAt(%d): hash differs", want.Height)
+		}
+		for _, wt := range want.Txs {
+			gt, h, err := s.Tx(wt.ID())
+			if err != nil || h != want.Hash() || !bytes.Equal(gt.Bytes(), wt.Bytes()) {
+				t.Errorf("Tx(%s): %v", wt.ID(), err)
+			}
+		}
+	}
+	if s.Tip() != len(chain)-1 {
+		t.Errorf("Tip: got %d, want %d", s.Tip(), len(chain)-1)
+	}
+}
+
+func TestPutRead(t *testing.T) {
+	dir := t.TempDir()
+	chain := testChain(10, 3)
+
+	// 小文件上限以触发轮换
+	s, err := Open(dir, Options{MaxFileSize: 1024})
+	if err != nil {
+		t.Fatal(err)
+	}
+	putAll(t, s, chain)
+	checkAll(t, s, chain)
+
+	if _, err := s.Put(chain[0]); !errors.Is(err, ErrExists) {
+		t.Errorf("duplicate Put: got %v", err)
+	}
+	if _, err := s.Block(block.Hash{}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("missing block: got %v", err)
+	}
+	s.Close()
+
+	if files, _ := filepath.Glob(filepath.Join(dir, "blk*.dat")); len(files) < 2 {
+		t.Errorf("expected rotation, got %d files", len(files))
+	}
+	s, err = Open(dir, Options{MaxFileSize: 1024})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	checkAll(t, s, chain)
+}
+
+func TestRecoverTornRecord(t *testing.T) {
+	dir := t.TempDir()
+	chain := testChain(3, 2)
+
+	s, err := Open(dir, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	putAll(t, s, chain[:2])
+	s.Close()
+
+	// 模拟写入中途崩溃：数据文件末尾是半条记录
+	rec := makeRecord(mustPayload(t, chain[2]))
+	path := filepath.Join(dir, "blk00000.dat")
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = f.Write(rec[:len(rec)/2]); err != nil {
+		t.Fatal(err)
+	}
+	if err = f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	s, err = Open(dir, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	checkAll(t, s, chain[:2])
+
+	// 截除后可继续写入
+	putAll(t, s, chain[2:])
+	checkAll(t, s, chain)
+}
+
+func TestRecoverIndex(t *testing.T) {
+	dir := t.TempDir()
+	chain := testChain(4, 2)
+
+	s, err := Open(dir, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	putAll(t, s, chain)
+	s.Close()
+
+	// 模拟数据已写入而索引残缺
+	path := filepath.Join(dir, indexName)
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Truncate(path, fi.Size()-blockEntrySize); err != nil {
+		t.Fatal(err)
+	}
+	s, err = Open(dir, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkAll(t, s, chain)
+	s.Close()
+
+	// 索引完全丢失时全部重建
+	if err = os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	s, err = Open(dir, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	checkAll(t, s, chain)
+}
+
+func TestPutInvalid(t *testing.T) {
+	s, err := Open(t.TempDir(), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	// 未知版本的交易无法紧凑编码，存入后将不可读取
+	b := testChain(1, 1)[0]
+	h := b.Txs[0].Header
+	h.Version = 99
+	b.Txs[0] = tx.New(h, b.Txs[0].Body)
+
+	if _, err := s.Put(b); !errors.Is(err, tx.ErrVersion) {
+		t.Errorf("got %v, want ErrVersion", err)
+	}
+	if s.Tip() != -1 {
+		t.Errorf("Tip: got %d", s.Tip())
+	}
+}
+
+// 校验通过但无法解码的记录不被截除。
+func TestRecoverUndecodable(t *testing.T) {
+	dir := t.TempDir()
+	chain := testChain(3, 1)
+
+	s, err := Open(dir, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	putAll(t, s, chain[:2])
+	s.Close()
+
+	// 无法解码的记录之后跟随有效记录，且索引需重建
+	path := filepath.Join(dir, "blk00000.dat")
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = f.Write(makeRecord([]byte{1, 2, 3})); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = f.Write(makeRecord(mustPayload(t, chain[2]))); err != nil {
+		t.Fatal(err)
+	}
+	if err = f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Remove(filepath.Join(dir, indexName)); err != nil {
+		t.Fatal(err)
+	}
+	before, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = Open(dir, Options{}); !errors.Is(err, ErrCorrupt) {
+		t.Fatalf("got %v, want ErrCorrupt", err)
+	}
+	after, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.Size() != before.Size() {
+		t.Errorf("data truncated: %d -> %d", before.Size(), after.Size())
+	}
+}
+
+// 重新打开后的并发读取（需 -race 检查）。
+func TestConcurrentRead(t *testing.T) {
+	dir := t.TempDir()
+	chain := testChain(12, 2)
+
+	s, err := Open(dir, Options{MaxFileSize: 1024})
+	if err != nil {
+		t.Fatal(err)
+	}
+	putAll(t, s, chain)
+	s.Close()
+
+	// 多轮重新打开，每轮读取用的文件均未缓存
+	for round := 0; round < 20; round++ {
+		s, err = Open(dir, Options{MaxFileSize: 1024})
+		if err != nil {
+			t.Fatal(err)
+		}
+		readAll(t, s, chain, 8)
+		s.Close()
+	}
+}
+
+// 以 n 个协程并发读取全部区块和交易。
+func readAll(t *testing.T, s *Store, chain []*block.Block, n int) {
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+
+	for g := 0; g < n; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			<-start
+			for i := range chain {
+				// 各协程以不同次序读取，争用不同的文件
+				want := chain[(i+g*5)%len(chain)]
+				got, err := s.BlockAt(want.Height)
+				if err != nil || got.Hash() != want.Hash() {
+					t.Errorf("BlockAt(%d): %v", want.Height, err)
+					return
+				}
+				if _, _, err = s.Tx(want.Txs[0].ID()); err != nil {
+					t.Errorf("Tx(%s): %v", want.Txs[0].ID(), err)
+					return
+				}
+			}
+		}(g)
+	}
+	close(start)
+	wg.Wait()
+}
+
+// 区块记录的数据部分。
+func mustPayload(t *testing.T, b *block.Block) []byte {
+	t.Helper()
+
+	p, _, err := encodeBlock(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
@@ -0,0 +1,135 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package tx
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/cxio/cbase/cerror"
+	"github.com/cxio/cbase/paddr"
+)
+
+const (
+	// 当前交易版本。
+	// 版本2增加时间锁（锁定高度和失效高度）。
+	// 各版本的字段定义参见 versions。
+	Version = 2
+
+	// 收益分成上限（n/100）。
+	MaxScale = 100
+)
+
+var (
+	// 版本不支持。
+	ErrVersion = cerror.New(2101, "不支持的交易版本")
+
+	// 交易体哈希不符。
+	ErrHashBody = cerror.New(2102, "交易体哈希不匹配")
+
+	// 公钥地址长度错误。
+	ErrPKAddr = cerror.New(2103, "公钥地址长度错误")
+
+	// 收益分成错误。
+	ErrScale = cerror.New(2104, "收益分成比例错误")
+
+	// 输入重复。
+	ErrDupVin = cerror.New(2105, "输入项重复")
+
+	// 无输出。
+	ErrNoVout = cerror.New(2106, "交易没有输出项")
+
+	// 空输出。
+	ErrEmptyVout = cerror.New(2107, "输出项无内容")
+
+	// 金额错误。
+	ErrAmount = cerror.New(2108, "币金金额无效")
+
+	// 时间锁范围错误。
+	ErrLockRange = cerror.New(2109, "失效高度低于锁定高度")
+
+	// 版本不支持时间锁。
+	ErrLockVersion = cerror.New(2110, "该交易版本不支持时间锁")
+)
+
+// Check 检查交易的合法性。
+// 仅检查交易自身的结构和数据，不涉及输入源的存在性和脚本执行。
+// 返回发现的全部问题，合法时为nil。
+func (t *Tx) Check() []error {
+	var errs []error
+
+	h := &t.Header
+	spec, ok := versions[h.Version]
+	if !ok {
+		errs = append(errs, &VersionError{h.Version})
+	}
+	if ok && !spec.timelock && (h.LockHeight != 0 || h.Expiry != 0) {
+		errs = append(errs, ErrLockVersion.With(h.Version))
+	}
+	if h.Expiry != 0 && h.Expiry < h.LockHeight {
+		errs = append(errs, ErrLockRange)
+	}
+	if !bytes.Equal(h.HashBody, t.Body.Hash()) {
+		errs = append(errs, ErrHashBody)
+	}
+	if !validPKAddr(h.Minter) {
+		errs = append(errs, fmt.Errorf(_T("铸造地址：%w"), ErrPKAddr))
+	}
+	if h.Scale > MaxScale || h.Scale > 0 && h.Staker == nil {
+		errs = append(errs, ErrScale)
+	}
+	if h.Staker != nil && !validPKAddr(h.Staker) {
+		errs = append(errs, fmt.Errorf(_T("收益地址：%w"), ErrPKAddr))
+	}
+	return append(errs, t.Body.check()...)
+}
+
+// 检查交易体。
+func (b *Body) check() []error {
+	var errs []error
+	seen := make(map[Vin]bool, len(b.vins))
+
+	for i, in := range b.vins {
+		if seen[in] {
+			errs = append(errs, fmt.Errorf(_T("输入 %d：%w"), i, ErrDupVin))
+		}
+		seen[in] = true
+	}
+	if len(b.vouts) == 0 {
+		errs = append(errs, ErrNoVout)
+	}
+	for i, out := range b.vouts {
+		if err := out.check(); err != nil {
+			errs = append(errs, fmt.Errorf(_T("输出 %d：%w"), i, err))
+		}
+	}
+	return errs
+}
+
+// 检查输出项。
+func (v Vout) check() error {
+	switch {
+	case v.coin != nil:
+		if v.coin.Amount <= 0 {
+			return ErrAmount
+		}
+		if !validPKAddr(v.coin.Receiver) {
+			return ErrPKAddr
+		}
+	case v.credit != nil:
+		if !validPKAddr(v.credit.Receiver) {
+			return ErrPKAddr
+		}
+	case v.evidence == nil:
+		return ErrEmptyVout
+	}
+	return nil
+}
+
+// 公钥地址长度是否合法。
+// 单签名为20字节，多重签名前置 n/T 配比为22字节。
+func validPKAddr(pkh PKAddr) bool {
+	n := len(pkh)
+	return n == paddr.HashSize || n == paddr.HashSize+2
+}
@@ -0,0 +1,209 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package tx
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/cxio/cbase/cerror"
+)
+
+// 输出类型标识（序列化用）。
+const (
+	kindCoin     = 1
+	kindCredit   = 2
+	kindEvidence = 3
+)
+
+// 单个变长字段的最大长度。
+// 防止恶意数据导致超大内存分配。
+const maxFieldSize = 1 << 24
+
+var (
+	// 数据不足。
+	ErrShortData = cerror.New(2001, "交易数据不完整")
+
+	// 字段过长。
+	ErrFieldSize = cerror.New(2002, "交易字段长度超出上限")
+
+	// 未知输出类型。
+	ErrOutKind = cerror.New(2003, "未知的输出类型")
+
+	// 多余数据。
+	ErrTrailing = cerror.New(2004, "交易数据末尾有多余字节")
+
+	// ID文本无效。
+	ErrIDText = cerror.New(2005, "ID文本格式无效")
+)
+
+//
+// 编码器
+///////////////////////////////////////////////////////////////////////////////
+
+// 序列化编码器。
+// 整数采用大端字节序，变长数据前置 uvarint 长度。
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) uint8(v uint8) {
+	e.buf = append(e.buf, v)
+}
+
+func (e *encoder) uint32(v uint32) {
+	e.buf = binary.BigEndian.AppendUint32(e.buf, v)
+}
+
+func (e *encoder) uint64(v uint64) {
+	e.buf = binary.BigEndian.AppendUint64(e.buf, v)
+}
+
+func (e *encoder) uvarint(v uint64) {
+	e.buf = binary.AppendUvarint(e.buf, v)
+}
+
+func (e *encoder) varint(v int64) {
+	e.buf = binary.AppendVarint(e.buf, v)
+}
+
+// 定长数据。
+func (e *encoder) fixed(b []byte) {
+	e.buf = append(e.buf, b...)
+}
+
+// 变长数据。
+func (e *encoder) bytes(b []byte) {
+	e.uvarint(uint64(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+//
+// 解码器
+///////////////////////////////////////////////////////////////////////////////
+
+// 序列化解码器。
+// 首个错误会被记录，之后的读取都返回零值，调用者最后检查 err 即可。
+type decoder struct {
+	buf []byte
+	err error
+}
+
+// 读取 n 字节。
+func (d *decoder) next(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n > len(d.buf) {
+		d.err = ErrShortData
+		return nil
+	}
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+	return b
+}
+
+func (d *decoder) uint8() uint8 {
+	if b := d.next(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (d *decoder) uint32() uint32 {
+	if b := d.next(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (d *decoder) uint64() uint64 {
+	if b := d.next(8); b != nil {
+		return binary.BigEndian.Uint64(b)
+	}
+	return 0
+}
+
+func (d *decoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(d.buf)
+	if n <= 0 {
+		d.err = ErrShortData
+		return 0
+	}
+	d.buf = d.buf[n:]
+	return v
+}
+
+func (d *decoder) varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(d.buf)
+	if n <= 0 {
+		d.err = ErrShortData
+		return 0
+	}
+	d.buf = d.buf[n:]
+	return v
+}
+
+// 读取 uvarint 编码的32位整数。
+func (d *decoder) uint32v() uint32 {
+	v := d.uvarint()
+	if v > math.MaxUint32 {
+		d.setErr(ErrFieldSize)
+		return 0
+	}
+	return uint32(v)
+}
+
+// 读取定长数据到目标。
+func (d *decoder) fixed(dst []byte) {
+	copy(dst, d.next(len(dst)))
+}
+
+// 读取变长数据。
+// 返回新分配的副本，长度为零时返回nil。
+func (d *decoder) bytes() []byte {
+	n := d.uvarint()
+	if n > maxFieldSize {
+		d.setErr(ErrFieldSize)
+		return nil
+	}
+	b := d.next(int(n))
+	if len(b) == 0 {
+		return nil
+	}
+	return append([]byte(nil), b...)
+}
+
+// 读取条目数。
+// 每个条目至少占用 min 字节，据此检查数量的合理性。
+func (d *decoder) count(min int) int {
+	n := d.uvarint()
+	if n > math.MaxInt32 || int(n)*min > len(d.buf) {
+		d.setErr(ErrShortData)
+		return 0
+	}
+	return int(n)
+}
+
+// 设置错误（仅保留首个）。
+func (d *decoder) setErr(err error) {
+	if d.err == nil {
+		d.err = err
+	}
+}
+
+// 完成解码。
+// 检查是否有多余的数据。
+func (d *decoder) finish() error {
+	if d.err == nil && len(d.buf) > 0 {
+		d.err = ErrTrailing
+	}
+	return d.err
+}
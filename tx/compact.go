@@ -0,0 +1,365 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package tx
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/cxio/cbase/cerror"
+)
+
+// 紧凑格式的输出类型标识。
+// 金额超出压缩范围的币金输出使用 kindCoinRaw。
+const kindCoinRaw = 4
+
+// 可压缩的金额上限。
+// 保证压缩计算不溢出。
+const maxCompressAmount = 1 << 60
+
+var (
+	// 地址表引用越界。
+	ErrCompactRef = cerror.New(2006, "紧凑格式的地址引用越界")
+
+	// 未知脚本模板。
+	ErrTemplate = cerror.New(2007, "未知的脚本模板")
+
+	// 非规范的压缩金额。
+	ErrCompactAmount = cerror.New(2008, "无效的压缩金额")
+
+	// 模板登记错误。
+	ErrTemplateID = cerror.New(2009, "脚本模板ID无效或已登记")
+)
+
+// 脚本模板登记表。
+var templates = struct {
+	sync.RWMutex
+	byID     map[uint64][]byte
+	byScript map[string]uint64
+}{
+	byID:     make(map[uint64][]byte),
+	byScript: make(map[string]uint64),
+}
+
+// RegisterTemplate 登记标准脚本模板。
+// 紧凑格式中与模板完全相同的脚本以模板ID代替。
+// ID 须大于零且未被使用，同一脚本不可重复登记。
+// 注：ID一经用于存储即不可更改，通常在包初始化时登记。
+func RegisterTemplate(id uint64, script []byte) error {
+	templates.Lock()
+	defer templates.Unlock()
+
+	if id == 0 || len(script) == 0 {
+		return ErrTemplateID.With(id)
+	}
+	if _, ok := templates.byID[id]; ok {
+		return ErrTemplateID.With(id)
+	}
+	if _, ok := templates.byScript[string(script)]; ok {
+		return ErrTemplateID.With(id)
+	}
+	templates.byID[id] = append([]byte(nil), script...)
+	templates.byScript[string(script)] = id
+
+	return nil
+}
+
+// CompressAmount 压缩金额。
+// 末尾的零（最多9个）计入指数，常见的整额金额因此很短。
+// 结果以 uvarint 存储，DecompressAmount 为其逆运算。
+// 仅适用于不超过 1<<60 的金额。
+func CompressAmount(n uint64) uint64 {
+	if n == 0 {
+		return 0
+	}
+	e := uint64(0)
+	for n%10 == 0 && e < 9 {
+		n /= 10
+		e++
+	}
+	if e < 9 {
+		d := n % 10
+		n /= 10
+		return 1 + (n*9+d-1)*10 + e
+	}
+	return 1 + (n-1)*10 + 9
+}
+
+// DecompressAmount 解压金额。
+// 参数须为 CompressAmount 的结果。
+func DecompressAmount(x uint64) uint64 {
+	if x == 0 {
+		return 0
+	}
+	x--
+	e := x % 10
+	x /= 10
+
+	var n uint64
+	if e < 9 {
+		d := x%9 + 1
+		x /= 9
+		n = x*10 + d
+	} else {
+		n = x + 1
+	}
+	for ; e > 0; e-- {
+		n *= 10
+	}
+	return n
+}
+
+// Compact 交易的紧凑格式。
+// 用于本地存储，与网络传输的规范格式（Bytes）相互独立：
+//   - 整数为 uvarint，币金金额经 CompressAmount 压缩。
+//   - 交易中的公钥地址去重后集中存放，各处以序位引用。
+//   - 已登记模板的脚本以模板ID代替。
+//   - 交易体哈希与计算值一致时省略。
+//
+// 布局依版本而定，未知版本返回 *VersionError。
+func (t *Tx) Compact() ([]byte, error) {
+	var e encoder
+	c := compactor{refs: make(map[string]uint64)}
+
+	h := &t.Header
+	spec, ok := versions[h.Version]
+	if !ok {
+		return nil, &VersionError{h.Version}
+	}
+	// 地址表
+	c.add(h.Minter)
+	c.add(h.Staker)
+	for _, v := range t.Body.vouts {
+		c.add(v.Receiver())
+	}
+	e.uvarint(uint64(uint32(h.Version)))
+	e.uvarint(uint64(h.Timestamp))
+	e.fixed(h.BlockLink[:])
+	e.uvarint(uint64(len(c.table)))
+	for _, a := range c.table {
+		e.bytes(a)
+	}
+	e.uvarint(c.ref(h.Minter))
+	e.uint8(h.Scale)
+	e.uvarint(c.ref(h.Staker))
+	if spec.timelock {
+		e.uvarint(uint64(h.LockHeight))
+		e.uvarint(uint64(h.Expiry))
+	}
+	if bytes.Equal(h.HashBody, t.Body.Hash()) {
+		e.uint8(0)
+	} else {
+		var hb [HashBodySize]byte
+		copy(hb[:], h.HashBody)
+		e.uint8(1)
+		e.fixed(hb[:])
+	}
+	e.uvarint(uint64(len(t.Body.vins)))
+	for _, in := range t.Body.vins {
+		e.fixed(in[:])
+	}
+	e.uvarint(uint64(len(t.Body.vouts)))
+	for _, v := range t.Body.vouts {
+		c.vout(&e, v)
+	}
+	return e.buf, nil
+}
+
+// DecodeCompact 解码紧凑格式的交易。
+// 数据须完整且无多余字节，未知版本返回 *VersionError。
+func DecodeCompact(data []byte) (*Tx, error) {
+	d := &decoder{buf: data}
+	t := new(Tx)
+	h := &t.Header
+
+	ver := d.uvarint()
+	h.Version = int32(ver)
+
+	spec, ok := versions[h.Version]
+	if !ok || ver > 0xffffffff {
+		d.setErr(&VersionError{h.Version})
+	}
+	h.Timestamp = int64(d.uvarint())
+	d.fixed(h.BlockLink[:])
+
+	table := make([]PKAddr, d.count(1))
+	for i := range table {
+		table[i] = d.bytes()
+	}
+	h.Minter = derefAddr(d, table)
+	h.Scale = d.uint8()
+	h.Staker = derefAddr(d, table)
+
+	if spec.timelock {
+		h.LockHeight = d.uint32v()
+		h.Expiry = d.uint32v()
+	}
+	explicit := d.uint8()
+	if explicit > 1 {
+		d.setErr(ErrCompactRef)
+	}
+	if explicit == 1 {
+		h.HashBody = make([]byte, HashBodySize)
+		d.fixed(h.HashBody)
+	}
+	b := &t.Body
+	b.vins = make([]Vin, d.count(InIDSize))
+	for i := range b.vins {
+		d.fixed(b.vins[i][:])
+	}
+	b.vouts = make([]Vout, d.count(2))
+	for i := range b.vouts {
+		b.vouts[i] = decodeCompactVout(d, table)
+	}
+	if err := d.finish(); err != nil {
+		return nil, err
+	}
+	if explicit == 0 {
+		h.HashBody = b.Hash()
+	}
+	return t, nil
+}
+
+//
+// 私有辅助
+///////////////////////////////////////////////////////////////////////////////
+
+// 紧凑编码的地址表。
+type compactor struct {
+	table []PKAddr
+	refs  map[string]uint64 // 地址到引用值（序位+1）
+}
+
+// 加入地址表，空地址忽略。
+func (c *compactor) add(a PKAddr) {
+	if len(a) == 0 {
+		return
+	}
+	if _, ok := c.refs[string(a)]; !ok {
+		c.table = append(c.table, a)
+		c.refs[string(a)] = uint64(len(c.table))
+	}
+}
+
+// 地址的引用值，空地址为0。
+func (c *compactor) ref(a PKAddr) uint64 {
+	return c.refs[string(a)]
+}
+
+// 紧凑编码输出项。
+func (c *compactor) vout(e *encoder, v Vout) {
+	switch {
+	case v.coin != nil:
+		if a := v.coin.Amount; a >= 0 && a <= maxCompressAmount {
+			e.uint8(kindCoin)
+			e.uvarint(c.ref(v.coin.Receiver))
+			e.uvarint(CompressAmount(uint64(a)))
+		} else {
+			e.uint8(kindCoinRaw)
+			e.uvarint(c.ref(v.coin.Receiver))
+			e.varint(a)
+		}
+		encodeScript(e, v.coin.Script)
+	case v.credit != nil:
+		e.uint8(kindCredit)
+		e.uvarint(c.ref(v.credit.Receiver))
+		e.bytes(v.credit.Creator)
+		e.bytes(v.credit.Description)
+		encodeScript(e, v.credit.Script)
+		e.bytes(v.credit.Attachment)
+	case v.evidence != nil:
+		e.uint8(kindEvidence)
+		e.bytes(v.evidence.Title)
+		e.bytes(v.evidence.Content)
+		encodeScript(e, v.evidence.Script)
+		e.bytes(v.evidence.Attachment)
+	default:
+		e.uint8(0)
+	}
+}
+
+// 紧凑解码输出项。
+func decodeCompactVout(d *decoder, table []PKAddr) (v Vout) {
+	switch d.uint8() {
+	case kindCoin:
+		c := &Coin{Receiver: derefAddr(d, table)}
+		x := d.uvarint()
+		if n := DecompressAmount(x); n <= maxCompressAmount && CompressAmount(n) == x {
+			c.Amount = int64(n)
+		} else {
+			d.setErr(ErrCompactAmount)
+		}
+		c.Script = decodeScript(d)
+		v.coin = c
+	case kindCoinRaw:
+		v.coin = &Coin{
+			Receiver: derefAddr(d, table),
+			Amount:   d.varint(),
+			Script:   decodeScript(d),
+		}
+	case kindCredit:
+		v.credit = &Credit{
+			Receiver:    derefAddr(d, table),
+			Creator:     d.bytes(),
+			Description: d.bytes(),
+			Script:      decodeScript(d),
+			Attachment:  d.bytes(),
+		}
+	case kindEvidence:
+		v.evidence = &Evidence{
+			Title:      d.bytes(),
+			Content:    d.bytes(),
+			Script:     decodeScript(d),
+			Attachment: d.bytes(),
+		}
+	default:
+		d.setErr(ErrOutKind)
+	}
+	return
+}
+
+// 解析地址引用。
+func derefAddr(d *decoder, table []PKAddr) PKAddr {
+	r := d.uvarint()
+	if r == 0 {
+		return nil
+	}
+	if r > uint64(len(table)) {
+		d.setErr(ErrCompactRef.With(r))
+		return nil
+	}
+	return table[r-1]
+}
+
+// 编码脚本。
+// 前置 uvarint 标记：0 表示随后为原始脚本，否则为模板ID。
+func encodeScript(e *encoder, script []byte) {
+	templates.RLock()
+	id, ok := templates.byScript[string(script)]
+	templates.RUnlock()
+
+	if ok {
+		e.uvarint(id)
+		return
+	}
+	e.uvarint(0)
+	e.bytes(script)
+}
+
+// 解码脚本。
+func decodeScript(d *decoder) []byte {
+	id := d.uvarint()
+	if id == 0 {
+		return d.bytes()
+	}
+	templates.RLock()
+	s, ok := templates.byID[id]
+	templates.RUnlock()
+
+	if !ok {
+		d.setErr(ErrTemplate.With(id))
+		return nil
+	}
+	return append([]byte(nil), s...)
+}
@@ -0,0 +1,147 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package tx_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/cxio/cbase/tx"
+)
+
+// 样本交易的币金脚本登记为模板1。
+func init() {
+	if err := tx.RegisterTemplate(1, []byte{1, 2}); err != nil {
+		panic(err)
+	}
+}
+
+func TestCompressAmount(t *testing.T) {
+	tests := []struct {
+		n, x uint64
+	}{
+		{0, 0},
+		{1, 1},
+		{100000000, 9},
+		{5000000000, 50},
+		{2100000000000000, 0x1406f40},
+	}
+	for _, tt := range tests {
+		if x := tx.CompressAmount(tt.n); x != tt.x {
+			t.Errorf("CompressAmount(%d) = %#x, want %#x", tt.n, x, tt.x)
+		}
+		if n := tx.DecompressAmount(tt.x); n != tt.n {
+			t.Errorf("DecompressAmount(%#x) = %d, want %d", tt.x, n, tt.n)
+		}
+	}
+}
+
+func TestRegisterTemplate(t *testing.T) {
+	if err := tx.RegisterTemplate(1, []byte{9}); !errors.Is(err, tx.ErrTemplateID) {
+		t.Errorf("duplicate id: got %v", err)
+	}
+	if err := tx.RegisterTemplate(2, []byte{1, 2}); !errors.Is(err, tx.ErrTemplateID) {
+		t.Errorf("duplicate script: got %v", err)
+	}
+}
+
+// 紧凑编码，失败时终止测试。
+func mustCompact(t testing.TB, x *tx.Tx) []byte {
+	t.Helper()
+
+	data, err := x.Compact()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestCompact(t *testing.T) {
+	for _, x := range []*tx.Tx{versionedTx(1), versionedTx(2)} {
+		data := mustCompact(t, x)
+
+		if len(data) >= len(x.Bytes()) {
+			t.Errorf("v%d: compact %d bytes, canonical %d", x.Header.Version, len(data), len(x.Bytes()))
+		}
+		got, err := tx.DecodeCompact(data)
+		if err != nil {
+			t.Fatalf("v%d: %v", x.Header.Version, err)
+		}
+		if !bytes.Equal(got.Bytes(), x.Bytes()) {
+			t.Errorf("v%d: round trip differs", x.Header.Version)
+		}
+	}
+	// 引用越界
+	data := mustCompact(t, versionedTx(2))
+	data[len(data)-1] = 0xff
+	if _, err := tx.DecodeCompact(data); err == nil {
+		t.Error("corrupted data decoded")
+	}
+	// 未知版本不可编码
+	var ve *tx.VersionError
+	if _, err := versionedTx(99).Compact(); !errors.As(err, &ve) || ve.Version != 99 {
+		t.Errorf("unknown version: got %v", err)
+	}
+}
+
+// 规范格式可解码的交易，紧凑格式往返后须不变。
+func FuzzCompact(f *testing.F) {
+	f.Add(versionedTx(1).Bytes())
+	f.Add(versionedTx(2).Bytes())
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		x, err := tx.Decode(data)
+		if err != nil {
+			return
+		}
+		c, err := x.Compact()
+		if err != nil {
+			// 仅未知版本不可编码
+			if !errors.Is(err, tx.ErrVersion) || tx.KnownVersion(x.Header.Version) {
+				t.Fatalf("Compact: %v", err)
+			}
+			return
+		}
+		got, err := tx.DecodeCompact(c)
+		if err != nil {
+			t.Fatalf("DecodeCompact: %v", err)
+		}
+		if !bytes.Equal(got.Bytes(), data) {
+			t.Fatal("round trip differs")
+		}
+	})
+}
+
+// 任意数据不可导致异常，可解码者再次编码后须一致。
+func FuzzDecodeCompact(f *testing.F) {
+	f.Add(mustCompact(f, versionedTx(1)))
+	f.Add(mustCompact(f, versionedTx(2)))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		x, err := tx.DecodeCompact(data)
+		if err != nil {
+			return
+		}
+		y, err := tx.DecodeCompact(mustCompact(t, x))
+		if err != nil {
+			t.Fatalf("re-decode: %v", err)
+		}
+		if !bytes.Equal(x.Bytes(), y.Bytes()) {
+			t.Fatal("round trip differs")
+		}
+	})
+}
+
+func FuzzAmount(f *testing.F) {
+	f.Add(uint64(0))
+	f.Add(uint64(123456789000))
+
+	f.Fuzz(func(t *testing.T, n uint64) {
+		n %= 1 << 60
+		if got := tx.DecompressAmount(tx.CompressAmount(n)); got != n {
+			t.Fatalf("%d: got %d", n, got)
+		}
+	})
+}
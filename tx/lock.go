@@ -0,0 +1,54 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package tx
+
+import (
+	"time"
+
+	"github.com/cxio/cbase/cerror"
+)
+
+// 交易时间戳相对当前时间的最大超前量。
+// 容许节点间的时钟偏差。
+const MaxFutureTime = 2 * time.Hour
+
+var (
+	// 尚未到达锁定高度。
+	ErrLocked = cerror.New(2111, "交易尚未到达锁定高度")
+
+	// 已超过失效高度。
+	ErrExpired = cerror.New(2112, "交易已过失效高度")
+
+	// 时间戳超前。
+	ErrFutureTime = cerror.New(2113, "交易时间戳超前于当前时间")
+)
+
+// Locked 在给定高度是否仍被锁定。
+func (h *Header) Locked(height int) bool {
+	return h.LockHeight != 0 && int64(height) < int64(h.LockHeight)
+}
+
+// Expired 在给定高度是否已失效。
+func (h *Header) Expired(height int) bool {
+	return h.Expiry != 0 && int64(height) > int64(h.Expiry)
+}
+
+// CheckFinal 检查交易能否被给定高度的区块打包。
+// height 为打包区块的高度，now 为当前时间。
+// 返回首个不满足的条件，可打包时为nil。
+// 注：结构性问题（如版本、时间锁范围）由 Check 检查。
+func (t *Tx) CheckFinal(height int, now time.Time) error {
+	h := &t.Header
+
+	if h.Expired(height) {
+		return ErrExpired.With(h.Expiry, height)
+	}
+	if h.Locked(height) {
+		return ErrLocked.With(h.LockHeight, height)
+	}
+	if h.Timestamp > now.Add(MaxFutureTime).UnixMilli() {
+		return ErrFutureTime.With(time.UnixMilli(h.Timestamp).UTC().Format(time.RFC3339))
+	}
+	return nil
+}
@@ -5,13 +5,26 @@
 package tx
 
 import (
+	"encoding/hex"
+
 	"github.com/cxio/cbase"
+	"github.com/cxio/cbase/chash"
 	"github.com/cxio/cbase/paddr"
+	"github.com/cxio/locale"
 )
 
+// 便捷引用。
+var _T = locale.GetText
+
 const (
 	// 输入源索引长度
 	InIDSize = cbase.KeyIDSize
+
+	// 交易ID长度
+	TxIDSize = 32
+
+	// 交易体哈希长度
+	HashBodySize = 32
 )
 
 // 公钥地址引用
@@ -19,19 +32,117 @@ type PKAddr = paddr.PKAddr
 
 // Header 交易头信息。
 // TxID: Hash(Header)
+// 时间锁字段自版本2起有效，零值表示无限制。
 type Header struct {
-	Version   int32    // 版本
-	Timestamp int64    // 交易时间戳（毫秒）
-	BlockLink [20]byte // 主链绑定
-	Minter    PKAddr   // 铸造地址
-	Scale     uint8    // 收益地址分成（n/100）
-	Staker    PKAddr   // 收益地址，可选
-	HashBody  []byte   // 交易数据体哈希（32）
+	Version    int32    // 版本
+	Timestamp  int64    // 交易时间戳（毫秒）
+	BlockLink  [20]byte // 主链绑定
+	Minter     PKAddr   // 铸造地址
+	Scale      uint8    // 收益地址分成（n/100）
+	Staker     PKAddr   // 收益地址，可选
+	LockHeight uint32   // 锁定高度：不早于此高度的区块打包（v2）
+	Expiry     uint32   // 失效高度：不晚于此高度的区块打包（v2）
+	HashBody   []byte   // 交易数据体哈希（32）
+}
+
+// TxID 交易ID。
+type TxID [TxIDSize]byte
+
+// String 十六进制表示。
+func (id TxID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// MarshalText 编码为十六进制文本。
+func (id TxID) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+// UnmarshalText 从十六进制文本解码。
+func (id *TxID) UnmarshalText(text []byte) error {
+	return unhexID(id[:], text)
+}
+
+// TxID 计算交易ID。
+func (h *Header) TxID() (id TxID) {
+	copy(id[:], chash.Sum256(1, h.Bytes()))
+	return
+}
+
+// Bytes 交易头序列化。
+func (h *Header) Bytes() []byte {
+	var e encoder
+	h.encode(&e)
+	return e.buf
+}
+
+// 交易头编码。
+// HashBody 为定长字段，长度不足时补零。
+// 支持时间锁的版本在收益地址之后编码时间锁字段。
+// 注：未知版本按最新版本的格式编码，由 Check 报告。
+func (h *Header) encode(e *encoder) {
+	var hb [HashBodySize]byte
+	copy(hb[:], h.HashBody)
+
+	spec, ok := versions[h.Version]
+	if !ok {
+		spec = versions[Version]
+	}
+
+	e.uint32(uint32(h.Version))
+	e.uint64(uint64(h.Timestamp))
+	e.fixed(h.BlockLink[:])
+	e.bytes(h.Minter)
+	e.uint8(h.Scale)
+	e.bytes(h.Staker)
+	if spec.timelock {
+		e.uint32(h.LockHeight)
+		e.uint32(h.Expiry)
+	}
+	e.fixed(hb[:])
+}
+
+// 交易头解码。
+// 按版本确定字段，未知版本时报告 *VersionError。
+func (h *Header) decode(d *decoder) {
+	h.Version = int32(d.uint32())
+
+	spec, ok := versions[h.Version]
+	if !ok {
+		d.setErr(&VersionError{h.Version})
+		return
+	}
+	h.Timestamp = int64(d.uint64())
+	d.fixed(h.BlockLink[:])
+	h.Minter = d.bytes()
+	h.Scale = d.uint8()
+	h.Staker = d.bytes()
+	if spec.timelock {
+		h.LockHeight = d.uint32()
+		h.Expiry = d.uint32()
+	}
+	h.HashBody = make([]byte, HashBodySize)
+	d.fixed(h.HashBody)
 }
 
 // Vin 输入项。
 type Vin [InIDSize]byte
 
+// String 十六进制表示。
+func (in Vin) String() string {
+	return hex.EncodeToString(in[:])
+}
+
+// MarshalText 编码为十六进制文本。
+func (in Vin) MarshalText() ([]byte, error) {
+	return []byte(in.String()), nil
+}
+
+// UnmarshalText 从十六进制文本解码。
+func (in *Vin) UnmarshalText(text []byte) error {
+	return unhexID(in[:], text)
+}
+
 // 输出：币金类。
 type Coin struct {
 	Receiver PKAddr // 接收者
@@ -65,8 +176,202 @@ type Vout struct {
 	evidence *Evidence // 证据类
 }
 
+// NewCoinOut 创建币金类输出。
+func NewCoinOut(c *Coin) Vout {
+	return Vout{coin: c}
+}
+
+// NewCreditOut 创建凭信类输出。
+func NewCreditOut(c *Credit) Vout {
+	return Vout{credit: c}
+}
+
+// NewEvidenceOut 创建证据类输出。
+func NewEvidenceOut(e *Evidence) Vout {
+	return Vout{evidence: e}
+}
+
+// Coin 获取币金数据，非币金类输出返回nil。
+func (v Vout) Coin() *Coin { return v.coin }
+
+// Credit 获取凭信数据，非凭信类输出返回nil。
+func (v Vout) Credit() *Credit { return v.credit }
+
+// Evidence 获取证据数据，非证据类输出返回nil。
+func (v Vout) Evidence() *Evidence { return v.evidence }
+
+// Receiver 获取输出的接收者。
+// 证据类输出无接收者，返回nil。
+func (v Vout) Receiver() PKAddr {
+	switch {
+	case v.coin != nil:
+		return v.coin.Receiver
+	case v.credit != nil:
+		return v.credit.Receiver
+	}
+	return nil
+}
+
+// 输出项编码。
+// 类型标识1字节，后跟各字段。
+func (v Vout) encode(e *encoder) {
+	switch {
+	case v.coin != nil:
+		e.uint8(kindCoin)
+		e.bytes(v.coin.Receiver)
+		e.varint(v.coin.Amount)
+		e.bytes(v.coin.Script)
+	case v.credit != nil:
+		e.uint8(kindCredit)
+		e.bytes(v.credit.Receiver)
+		e.bytes(v.credit.Creator)
+		e.bytes(v.credit.Description)
+		e.bytes(v.credit.Script)
+		e.bytes(v.credit.Attachment)
+	case v.evidence != nil:
+		e.uint8(kindEvidence)
+		e.bytes(v.evidence.Title)
+		e.bytes(v.evidence.Content)
+		e.bytes(v.evidence.Script)
+		e.bytes(v.evidence.Attachment)
+	default:
+		e.uint8(0)
+	}
+}
+
+// 输出项解码。
+func (v *Vout) decode(d *decoder) {
+	switch d.uint8() {
+	case kindCoin:
+		v.coin = &Coin{
+			Receiver: d.bytes(),
+			Amount:   d.varint(),
+			Script:   d.bytes(),
+		}
+	case kindCredit:
+		v.credit = &Credit{
+			Receiver:    d.bytes(),
+			Creator:     d.bytes(),
+			Description: d.bytes(),
+			Script:      d.bytes(),
+			Attachment:  d.bytes(),
+		}
+	case kindEvidence:
+		v.evidence = &Evidence{
+			Title:      d.bytes(),
+			Content:    d.bytes(),
+			Script:     d.bytes(),
+			Attachment: d.bytes(),
+		}
+	default:
+		d.setErr(ErrOutKind)
+	}
+}
+
 // Body 交易体结构。
 type Body struct {
 	vins  []Vin  // 输入集
 	vouts []Vout // 输出集
 }
+
+// NewBody 创建交易体。
+func NewBody(vins []Vin, vouts []Vout) Body {
+	return Body{vins: vins, vouts: vouts}
+}
+
+// Vins 获取输入集。
+func (b *Body) Vins() []Vin { return b.vins }
+
+// Vouts 获取输出集。
+func (b *Body) Vouts() []Vout { return b.vouts }
+
+// Hash 计算交易体哈希。
+// 即交易头中 HashBody 的值。
+func (b *Body) Hash() []byte {
+	return chash.Sum256(1, b.Bytes())
+}
+
+// Bytes 交易体序列化。
+func (b *Body) Bytes() []byte {
+	var e encoder
+	b.encode(&e)
+	return e.buf
+}
+
+// 交易体编码。
+func (b *Body) encode(e *encoder) {
+	e.uvarint(uint64(len(b.vins)))
+	for _, in := range b.vins {
+		e.fixed(in[:])
+	}
+	e.uvarint(uint64(len(b.vouts)))
+	for _, out := range b.vouts {
+		out.encode(e)
+	}
+}
+
+// 交易体解码。
+func (b *Body) decode(d *decoder) {
+	b.vins = make([]Vin, d.count(InIDSize))
+	for i := range b.vins {
+		d.fixed(b.vins[i][:])
+	}
+	// 输出项至少2字节
+	b.vouts = make([]Vout, d.count(2))
+	for i := range b.vouts {
+		b.vouts[i].decode(d)
+	}
+}
+
+// Tx 完整交易。
+type Tx struct {
+	Header Header
+	Body   Body
+}
+
+// New 创建交易。
+// 会计算交易体哈希并设置到交易头。
+func New(h Header, b Body) *Tx {
+	h.HashBody = b.Hash()
+	return &Tx{Header: h, Body: b}
+}
+
+// ID 交易ID。
+func (t *Tx) ID() TxID {
+	return t.Header.TxID()
+}
+
+// Bytes 交易序列化。
+// 格式：交易头 + 交易体。
+func (t *Tx) Bytes() []byte {
+	var e encoder
+	t.Header.encode(&e)
+	t.Body.encode(&e)
+	return e.buf
+}
+
+// 解码十六进制文本到定长ID。
+func unhexID(dst, text []byte) error {
+	if hex.DecodedLen(len(text)) != len(dst) {
+		return ErrIDText.With(string(text))
+	}
+	if _, err := hex.Decode(dst, text); err != nil {
+		return ErrIDText.With(string(text))
+	}
+	return nil
+}
+
+// Decode 解码交易。
+// 数据须完整且无多余字节，未知版本返回 *VersionError。
+func Decode(data []byte) (*Tx, error) {
+	d := &decoder{buf: data}
+	t := new(Tx)
+
+	t.Header.decode(d)
+	t.Body.decode(d)
+
+	if err := d.finish(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
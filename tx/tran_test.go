@@ -0,0 +1,116 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package tx_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cxio/cbase"
+	"github.com/cxio/cbase/tx"
+)
+
+// 构造测试用交易。
+func sampleTx() *tx.Tx {
+	var in tx.Vin
+	copy(in[:], cbase.KeyID(100, 2, 1))
+	pkh := bytes.Repeat([]byte{7}, 20)
+
+	body := tx.NewBody([]tx.Vin{in}, []tx.Vout{
+		tx.NewCoinOut(&tx.Coin{Receiver: pkh, Amount: 150000000, Script: []byte{1, 2}}),
+		tx.NewCreditOut(&tx.Credit{Receiver: pkh, Creator: []byte{9}, Description: []byte("credit")}),
+		tx.NewEvidenceOut(&tx.Evidence{Title: []byte("title"), Content: []byte("content")}),
+	})
+	return tx.New(tx.Header{Version: tx.Version, Timestamp: 1660000000000, Minter: pkh}, body)
+}
+
+func TestRoundTrip(t *testing.T) {
+	want := sampleTx()
+	data := want.Bytes()
+
+	got, err := tx.Decode(data)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if !bytes.Equal(got.Bytes(), data) {
+		t.Error("re-encoded bytes differ")
+	}
+	if got.ID() != want.ID() {
+		t.Errorf("TxID: got %s, want %s", got.ID(), want.ID())
+	}
+	if errs := got.Check(); errs != nil {
+		t.Errorf("Check: %v", errs)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	data := sampleTx().Bytes()
+
+	if _, err := tx.Decode(data[:len(data)-1]); err != tx.ErrShortData {
+		t.Errorf("truncated: got %v, want ErrShortData", err)
+	}
+	if _, err := tx.Decode(append(data, 0)); err != tx.ErrTrailing {
+		t.Errorf("trailing: got %v, want ErrTrailing", err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	x := sampleTx()
+	x.Header.Scale = 10
+	x.Body.Vouts()[0].Coin().Amount = 0
+
+	errs := x.Check()
+	for _, want := range []error{tx.ErrHashBody, tx.ErrScale, tx.ErrAmount} {
+		found := false
+		for _, err := range errs {
+			found = found || errors.Is(err, want)
+		}
+		if !found {
+			t.Errorf("Check: missing %v in %v", want, errs)
+		}
+	}
+}
+
+func TestTimelock(t *testing.T) {
+	x := sampleTx()
+	x.Header.LockHeight, x.Header.Expiry = 200, 300
+	x = tx.New(x.Header, x.Body)
+
+	// 版本2编码时间锁字段
+	got, err := tx.Decode(x.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Header.LockHeight != 200 || got.Header.Expiry != 300 {
+		t.Errorf("decoded lock: %d, %d", got.Header.LockHeight, got.Header.Expiry)
+	}
+	now := time.UnixMilli(x.Header.Timestamp)
+
+	tests := []struct {
+		height int
+		want   error
+	}{
+		{199, tx.ErrLocked},
+		{200, nil},
+		{300, nil},
+		{301, tx.ErrExpired},
+	}
+	for _, tt := range tests {
+		if err := x.CheckFinal(tt.height, now); !errors.Is(err, tt.want) {
+			t.Errorf("CheckFinal(%d): got %v, want %v", tt.height, err, tt.want)
+		}
+	}
+	if err := x.CheckFinal(250, now.Add(-3*time.Hour)); !errors.Is(err, tx.ErrFutureTime) {
+		t.Errorf("future timestamp: got %v", err)
+	}
+	// 版本1不支持时间锁，失效高度不得低于锁定高度
+	x.Header.Version = 1
+	x.Header.Expiry = 100
+	errs := x.Check()
+	if len(errs) != 2 || !errors.Is(errs[0], tx.ErrLockVersion) || !errors.Is(errs[1], tx.ErrLockRange) {
+		t.Errorf("Check: got %v", errs)
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package tx
+
+import (
+	"github.com/cxio/cbase/cerror"
+)
+
+// 降级丢失数据。
+var ErrDowngrade = cerror.New(2114, "转换到目标版本会丢失数据")
+
+// 版本规格。
+// 定义各版本的交易头字段和适用规则。
+type versionSpec struct {
+	timelock bool // 时间锁字段（LockHeight、Expiry）
+}
+
+// 已知版本集。
+// 新增版本时在此登记，并提供相应的转换规则。
+var versions = map[int32]versionSpec{
+	1: {},
+	2: {timelock: true},
+}
+
+// VersionError 未知的交易版本。
+// 兼容 errors.Is(err, ErrVersion)。
+type VersionError struct {
+	Version int32
+}
+
+// Error 错误消息。
+func (e *VersionError) Error() string {
+	return ErrVersion.With(e.Version).Error()
+}
+
+// Unwrap 返回 ErrVersion。
+func (e *VersionError) Unwrap() error {
+	return ErrVersion
+}
+
+// KnownVersion 是否为已知版本。
+func KnownVersion(ver int32) bool {
+	_, ok := versions[ver]
+	return ok
+}
+
+// Convert 转换交易到指定版本。
+// 升级总是成功，新增字段为零值；降级时若被移除的字段非零则返回 ErrDowngrade。
+// 交易头的版本改变，因此转换后的交易ID不同于原交易。
+// 原交易不被修改。
+func Convert(t *Tx, ver int32) (*Tx, error) {
+	from, ok := versions[t.Header.Version]
+	if !ok {
+		return nil, &VersionError{t.Header.Version}
+	}
+	to, ok := versions[ver]
+	if !ok {
+		return nil, &VersionError{ver}
+	}
+	h := t.Header
+	h.Version = ver
+
+	if from.timelock && !to.timelock {
+		if h.LockHeight != 0 || h.Expiry != 0 {
+			return nil, ErrDowngrade.With(ver)
+		}
+	}
+	return New(h, t.Body), nil
+}
+
+// Upgrade 升级交易到当前版本。
+func Upgrade(t *Tx) (*Tx, error) {
+	return Convert(t, Version)
+}
@@ -0,0 +1,108 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package tx_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cxio/cbase/tx"
+)
+
+var update = flag.Bool("update", false, "重新生成 testdata 中的黄金文件")
+
+// 各版本的样本交易。
+func versionedTx(ver int32) *tx.Tx {
+	x := sampleTx()
+	x.Header.Version = ver
+
+	if ver >= 2 {
+		x.Header.LockHeight, x.Header.Expiry = 1000, 2000
+	}
+	return tx.New(x.Header, x.Body)
+}
+
+// 黄金文件：首行为交易ID，次行为序列化数据，均为十六进制。
+// 已存储的交易在格式变更后须保持可解码且ID不变。
+func TestGolden(t *testing.T) {
+	for _, ver := range []int32{1, 2} {
+		path := filepath.Join("testdata", fmt.Sprintf("v%d.golden", ver))
+
+		if *update {
+			x := versionedTx(ver)
+			text := x.ID().String() + "\n" + hex.EncodeToString(x.Bytes()) + "\n"
+			if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Fields(string(raw))
+		if len(lines) != 2 {
+			t.Fatalf("%s: malformed golden file", path)
+		}
+		data, _ := hex.DecodeString(lines[1])
+
+		x, err := tx.Decode(data)
+		if err != nil {
+			t.Fatalf("v%d: %v", ver, err)
+		}
+		if x.Header.Version != ver || x.ID().String() != lines[0] {
+			t.Errorf("v%d: version %d, id %s", ver, x.Header.Version, x.ID())
+		}
+		if !bytes.Equal(x.Bytes(), data) {
+			t.Errorf("v%d: re-encoded bytes differ", ver)
+		}
+		if errs := x.Check(); errs != nil {
+			t.Errorf("v%d: Check: %v", ver, errs)
+		}
+	}
+}
+
+func TestUnknownVersion(t *testing.T) {
+	data := versionedTx(2).Bytes()
+	data[3] = 9
+
+	_, err := tx.Decode(data)
+	var ve *tx.VersionError
+
+	if !errors.As(err, &ve) || ve.Version != 9 || !errors.Is(err, tx.ErrVersion) {
+		t.Errorf("got %v, want *VersionError(9)", err)
+	}
+	if tx.KnownVersion(9) || !tx.KnownVersion(tx.Version) {
+		t.Error("KnownVersion")
+	}
+}
+
+func TestConvert(t *testing.T) {
+	v1 := versionedTx(1)
+
+	v2, err := tx.Upgrade(v1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v2.Header.Version != 2 || v2.ID() == v1.ID() {
+		t.Errorf("upgrade: version %d", v2.Header.Version)
+	}
+	back, err := tx.Convert(v2, 1)
+	if err != nil || back.ID() != v1.ID() {
+		t.Errorf("downgrade: %v", err)
+	}
+	// 时间锁数据无法降级
+	if _, err := tx.Convert(versionedTx(2), 1); !errors.Is(err, tx.ErrDowngrade) {
+		t.Errorf("lossy downgrade: got %v", err)
+	}
+	if _, err := tx.Convert(v1, 7); !errors.Is(err, tx.ErrVersion) {
+		t.Errorf("unknown target: got %v", err)
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+// Package vanity 靓号地址搜索。
+// 多协程并发生成密钥对，计算公钥地址并编码，直到文本地址匹配指定的前缀或正则式。
+package vanity
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"math"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cxio/cbase/base58"
+	"github.com/cxio/cbase/cerror"
+	"github.com/cxio/cbase/paddr"
+)
+
+// Base58 字母表大小。
+const alphabetSize = 58
+
+// 默认进度报告间隔。
+const defaultInterval = time.Second
+
+var (
+	// 未指定匹配条件。
+	ErrNoPattern = cerror.New(4001, "未指定匹配前缀或正则式")
+
+	// 前缀包含非Base58字符。
+	ErrPattern = cerror.New(4002, "匹配前缀包含非Base58字符")
+)
+
+// KeyGen 密钥对生成器。
+// 返回公钥和私钥，公钥用于构造公钥地址。
+type KeyGen func() (pub, priv []byte, err error)
+
+// Options 搜索配置。
+// Pattern 和 Regexp 二选一，同时设置时以 Regexp 为准。
+// 两者都仅针对分隔符之后的文本地址部分。
+type Options struct {
+	Prefix   string         // 地址标识前缀
+	Pattern  string         // 文本地址前缀
+	Regexp   *regexp.Regexp // 文本地址正则式
+	Workers  int            // 工作协程数，默认为CPU核数
+	KeyGen   KeyGen         // 密钥对生成器，默认为 Ed25519
+	Interval time.Duration  // 进度报告间隔，默认1秒
+	Progress func(Stats)    // 进度报告回调，可选
+}
+
+// Stats 搜索进度。
+type Stats struct {
+	Tries      uint64        // 已尝试次数
+	Elapsed    time.Duration // 已用时长
+	Rate       float64       // 每秒尝试次数
+	Difficulty float64       // 期望尝试次数（正则式时为0）
+	Chance     float64       // 截至目前应已找到的概率
+	ETA        time.Duration // 按当前速率的期望剩余时长（可能为负）
+}
+
+// Result 搜索结果。
+type Result struct {
+	Address string // 账户地址（含前缀）
+	PKAddr  paddr.PKAddr
+	PubKey  []byte // 公钥
+	PrivKey []byte // 私钥
+	Tries   uint64 // 总尝试次数
+}
+
+// Difficulty 匹配前缀的难度估算。
+// 即期望的尝试次数，按 Base58 字母表大小计算为 58^n。
+// 注：
+// 文本地址首字符并非均匀分布（受字节长度影响），此为近似值。
+func Difficulty(pattern string) float64 {
+	return math.Pow(alphabetSize, float64(len(pattern)))
+}
+
+// Search 搜索靓号地址。
+// 找到首个匹配即返回，ctx 被取消时返回其错误。
+func Search(ctx context.Context, opt Options) (*Result, error) {
+	match, diff, err := matcher(opt)
+	if err != nil {
+		return nil, err
+	}
+	gen := opt.KeyGen
+	if gen == nil {
+		gen = ed25519Key
+	}
+	n := opt.Workers
+	if n <= 0 {
+		n = runtime.NumCPU()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var tries atomic.Uint64
+	var wg sync.WaitGroup
+	found := make(chan *Result, 1)
+	fail := make(chan error, 1)
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r, err := work(ctx, opt.Prefix, gen, match, &tries)
+			switch {
+			case r != nil:
+				notify(found, r)
+			case err != nil:
+				notify(fail, err)
+			}
+		}()
+	}
+	var tick <-chan time.Time
+
+	if opt.Progress != nil {
+		iv := opt.Interval
+		if iv <= 0 {
+			iv = defaultInterval
+		}
+		t := time.NewTicker(iv)
+		defer t.Stop()
+		tick = t.C
+	}
+	start := time.Now()
+
+	for {
+		select {
+		case r := <-found:
+			cancel()
+			wg.Wait()
+			r.Tries = tries.Load()
+			return r, nil
+		case err := <-fail:
+			cancel()
+			wg.Wait()
+			return nil, err
+		case <-ctx.Done():
+			wg.Wait()
+			return nil, ctx.Err()
+		case <-tick:
+			opt.Progress(stats(tries.Load(), time.Since(start), diff))
+		}
+	}
+}
+
+//
+// 私有辅助
+///////////////////////////////////////////////////////////////////////////////
+
+// 构造匹配函数。
+// 返回匹配函数和难度值。
+func matcher(opt Options) (func(string) bool, float64, error) {
+	if opt.Regexp != nil {
+		return opt.Regexp.MatchString, 0, nil
+	}
+	if opt.Pattern == "" {
+		return nil, 0, ErrNoPattern
+	}
+	// 含非法字符时解码为空
+	if len(base58.Decode(opt.Pattern)) == 0 {
+		return nil, 0, ErrPattern
+	}
+	p := opt.Pattern
+
+	return func(s string) bool { return strings.HasPrefix(s, p) }, Difficulty(p), nil
+}
+
+// 工作协程主体。
+// 找到匹配时返回结果，被取消时返回nil。
+func work(ctx context.Context, prefix string, gen KeyGen, match func(string) bool, tries *atomic.Uint64) (*Result, error) {
+	// 文本地址起始位置
+	at := len(prefix) + 1
+
+	for ctx.Err() == nil {
+		pub, priv, err := gen()
+		if err != nil {
+			return nil, err
+		}
+		pkh := paddr.Hash(pub, nil)
+		addr := paddr.Encode(pkh, prefix)
+		tries.Add(1)
+
+		if match(addr[at:]) {
+			return &Result{Address: addr, PKAddr: pkh, PubKey: pub, PrivKey: priv}, nil
+		}
+	}
+	return nil, nil
+}
+
+// 非阻塞发送，仅保留首个值。
+func notify[T any](ch chan T, v T) {
+	select {
+	case ch <- v:
+	default:
+	}
+}
+
+// 计算进度统计。
+func stats(tries uint64, elapsed time.Duration, diff float64) Stats {
+	s := Stats{
+		Tries:      tries,
+		Elapsed:    elapsed,
+		Difficulty: diff,
+	}
+	if sec := elapsed.Seconds(); sec > 0 {
+		s.Rate = float64(tries) / sec
+	}
+	if diff > 0 {
+		s.Chance = 1 - math.Pow(1-1/diff, float64(tries))
+		if s.Rate > 0 {
+			s.ETA = time.Duration((diff - float64(tries)) / s.Rate * float64(time.Second))
+		}
+	}
+	return s
+}
+
+// 默认密钥对生成器（Ed25519）。
+func ed25519Key() ([]byte, []byte, error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	return pub, priv, err
+}
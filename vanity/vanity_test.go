@@ -0,0 +1,124 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package vanity
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cxio/cbase/paddr"
+)
+
+// 确定性的密钥生成器。
+// 公钥为递增计数的8字节编码，私钥为空。
+func counterGen(n *atomic.Uint64) KeyGen {
+	return func() ([]byte, []byte, error) {
+		pub := binary.BigEndian.AppendUint64(nil, n.Add(1)-1)
+		return pub, nil, nil
+	}
+}
+
+// 计数对应的文本地址（不含前缀）。
+func counterAddr(i uint64) string {
+	addr := paddr.Encode(paddr.Hash(binary.BigEndian.AppendUint64(nil, i), nil), "cx")
+	return addr[len("cx:"):]
+}
+
+func TestDifficulty(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    float64
+	}{
+		{"", 1},
+		{"a", 58},
+		{"abc", 58 * 58 * 58},
+		{"Hello", 656356768},
+	}
+	for _, tt := range tests {
+		if got := Difficulty(tt.pattern); got != tt.want {
+			t.Errorf("Difficulty(%q) = %v, want %v", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestPatternErrors(t *testing.T) {
+	ctx := context.Background()
+
+	if _, err := Search(ctx, Options{Prefix: "cx"}); !errors.Is(err, ErrNoPattern) {
+		t.Errorf("no pattern: got %v", err)
+	}
+	// 0、O、I、l 不是Base58字符
+	for _, p := range []string{"0a", "abO", "I", "xl"} {
+		if _, err := Search(ctx, Options{Prefix: "cx", Pattern: p}); !errors.Is(err, ErrPattern) {
+			t.Errorf("pattern %q: got %v", p, err)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	// 以计数 37 的地址前2字符为目标，单协程时必在其之前（含）找到
+	pattern := counterAddr(37)[:2]
+	var n atomic.Uint64
+
+	r, err := Search(context.Background(), Options{
+		Prefix:  "cx",
+		Pattern: pattern,
+		Workers: 1,
+		KeyGen:  counterGen(&n),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(r.Address, "cx:"+pattern) || r.Tries > 38 {
+		t.Errorf("got %s after %d tries", r.Address, r.Tries)
+	}
+	if paddr.Encode(paddr.Hash(r.PubKey, nil), "cx") != r.Address {
+		t.Error("result key does not derive the address")
+	}
+	// 首个匹配的计数
+	i := binary.BigEndian.Uint64(r.PubKey)
+	for k := uint64(0); k < i; k++ {
+		if strings.HasPrefix(counterAddr(k), pattern) {
+			t.Errorf("missed earlier match at %d", k)
+		}
+	}
+}
+
+func TestSearchCancel(t *testing.T) {
+	var n atomic.Uint64
+	var reports atomic.Int32
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := Search(ctx, Options{
+		Prefix:   "cx",
+		Pattern:  "zzzzzzzzzz",
+		Workers:  4,
+		KeyGen:   counterGen(&n),
+		Interval: 10 * time.Millisecond,
+		Progress: func(Stats) { reports.Add(1) },
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want DeadlineExceeded", err)
+	}
+	if d := time.Since(start); d > 2*time.Second {
+		t.Errorf("returned after %v", d)
+	}
+	if reports.Load() == 0 {
+		t.Error("no progress reported")
+	}
+	// 返回时全部工作协程已停止
+	before := n.Load()
+	time.Sleep(50 * time.Millisecond)
+	if after := n.Load(); after != before {
+		t.Errorf("workers still running: %d -> %d", before, after)
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package wallet
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+)
+
+// CSV 导出的列名。
+var csvHeader = []string{
+	"txid", "height", "time", "kind", "pending",
+	"received", "spent", "sent", "fee", "delta",
+	"credits_in", "credits_out", "label", "note",
+}
+
+// ExportCSV 以CSV格式导出历史记录。
+// 首行为列名，金额单位为聪。待确认记录的高度和时间为0。
+func (w *Watcher) ExportCSV(out io.Writer) error {
+	cw := csv.NewWriter(out)
+
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, r := range w.Records() {
+		err := cw.Write([]string{
+			r.TxID.String(),
+			strconv.Itoa(r.Height),
+			strconv.FormatInt(r.Time, 10),
+			r.Kind.String(),
+			strconv.FormatBool(r.Pending),
+			strconv.FormatInt(r.Received, 10),
+			strconv.FormatInt(r.Spent, 10),
+			strconv.FormatInt(r.Sent, 10),
+			strconv.FormatInt(r.Fee, 10),
+			strconv.FormatInt(r.Delta(), 10),
+			strconv.Itoa(r.CreditsIn),
+			strconv.Itoa(r.CreditsOut),
+			r.Label,
+			r.Note,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ExportJSON 以JSON数组格式导出历史记录。
+func (w *Watcher) ExportJSON(out io.Writer) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(w.Records())
+}
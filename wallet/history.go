@@ -0,0 +1,298 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package wallet
+
+import (
+	"github.com/cxio/cbase/block"
+	"github.com/cxio/cbase/cerror"
+	"github.com/cxio/cbase/tx"
+)
+
+// Kind 历史记录类型。
+type Kind int
+
+// 历史记录类型值。
+const (
+	KindIncoming Kind = iota + 1 // 收入
+	KindOutgoing                 // 支出
+	KindSelf                     // 自我转账（输出全部回到本钱包）
+	KindCredit                   // 凭信转移（无币金变化）
+)
+
+// 类型名称。
+var kindNames = map[Kind]string{
+	KindIncoming: "incoming",
+	KindOutgoing: "outgoing",
+	KindSelf:     "self",
+	KindCredit:   "credit",
+}
+
+// 未知记录类型。
+var ErrKind = cerror.New(7201, "未知的历史记录类型")
+
+// String 类型名称。
+func (k Kind) String() string {
+	if s, ok := kindNames[k]; ok {
+		return s
+	}
+	return "unknown"
+}
+
+// MarshalText 编码为类型名称。
+func (k Kind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// UnmarshalText 从类型名称解码。
+func (k *Kind) UnmarshalText(text []byte) error {
+	for v, s := range kindNames {
+		if s == string(text) {
+			*k = v
+			return nil
+		}
+	}
+	return ErrKind.With(string(text))
+}
+
+// Kind 历史条目的类型。
+func (e *Entry) Kind() Kind {
+	switch {
+	case e.Spent == 0 && e.Received > 0:
+		return KindIncoming
+	case e.Spent > 0 && e.Sent == 0:
+		return KindSelf
+	case e.Spent > 0:
+		return KindOutgoing
+	}
+	return KindCredit
+}
+
+// Meta 交易的标签和备注。
+type Meta struct {
+	Label string `json:"label,omitempty"`
+	Note  string `json:"note,omitempty"`
+}
+
+// Record 历史记录。
+// 在历史条目的基础上附加类型、确认状态和用户标注。
+type Record struct {
+	Entry
+	Kind    Kind   // 记录类型
+	Pending bool   // 是否待确认
+	Label   string // 标签
+	Note    string // 备注
+}
+
+// Balances 余额统计。
+type Balances struct {
+	Confirmed int64 // 已确认余额
+	Incoming  int64 // 待确认的收入
+	Outgoing  int64 // 被待确认交易花费的已确认币金
+}
+
+// Spendable 可花费余额。
+func (b Balances) Spendable() int64 {
+	return b.Confirmed - b.Outgoing
+}
+
+// Pending 计入待确认交易后的余额。
+func (b Balances) Pending() int64 {
+	return b.Confirmed - b.Outgoing + b.Incoming
+}
+
+// AddPending 加入待确认交易。
+// 与本钱包无关或已存在的交易被忽略，返回是否加入。
+// 注：待确认交易之间的依赖（花费另一待确认交易的输出）不被识别。
+func (w *Watcher) AddPending(t *tx.Tx) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	id := t.ID()
+	for _, p := range w.pending {
+		if p.ID() == id {
+			return false
+		}
+	}
+	if w.entry(t) == nil {
+		return false
+	}
+	w.pending = append(w.pending, t)
+
+	return true
+}
+
+// RemovePending 移除待确认交易。
+// 如交易被网络丢弃时，返回是否存在。
+func (w *Watcher) RemovePending(id tx.TxID) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for i, p := range w.pending {
+		if p.ID() == id {
+			w.pending = append(w.pending[:i], w.pending[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Pending 待确认交易集。
+// 按加入顺序排列。
+func (w *Watcher) Pending() []*tx.Tx {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	return append([]*tx.Tx(nil), w.pending...)
+}
+
+// Balances 已确认和待确认的余额统计。
+func (w *Watcher) Balances() Balances {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	var b Balances
+	used := w.pendingVins()
+
+	for _, o := range w.unspent {
+		if o.Coin == nil {
+			continue
+		}
+		b.Confirmed += o.Coin.Amount
+		if used[o.ID] {
+			b.Outgoing += o.Coin.Amount
+		}
+	}
+	for _, t := range w.pending {
+		if e := w.entry(t); e != nil {
+			b.Incoming += e.Received
+		}
+	}
+	return b
+}
+
+// SetLabel 设置交易标签。
+func (w *Watcher) SetLabel(id tx.TxID, label string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.metaOf(id).Label = label
+	w.tidyMeta(id)
+}
+
+// SetNote 设置交易备注。
+func (w *Watcher) SetNote(id tx.TxID, note string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.metaOf(id).Note = note
+	w.tidyMeta(id)
+}
+
+// Meta 获取交易的标签和备注。
+func (w *Watcher) Meta(id tx.TxID) Meta {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if m, ok := w.meta[id]; ok {
+		return *m
+	}
+	return Meta{}
+}
+
+// Records 全部历史记录。
+// 已确认的记录按区块顺序在前，待确认的按加入顺序在后。
+func (w *Watcher) Records() []Record {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	out := make([]Record, 0, len(w.history)+len(w.pending))
+
+	for _, e := range w.history {
+		out = append(out, w.record(*e, false))
+	}
+	for _, t := range w.pending {
+		if e := w.entry(t); e != nil {
+			out = append(out, w.record(*e, true))
+		}
+	}
+	return out
+}
+
+//
+// 私有辅助
+///////////////////////////////////////////////////////////////////////////////
+
+// 构造历史记录。
+func (w *Watcher) record(e Entry, pending bool) Record {
+	r := Record{Entry: e, Kind: e.Kind(), Pending: pending}
+
+	if m, ok := w.meta[e.TxID]; ok {
+		r.Label, r.Note = m.Label, m.Note
+	}
+	return r
+}
+
+// 获取或创建交易标注。
+func (w *Watcher) metaOf(id tx.TxID) *Meta {
+	m, ok := w.meta[id]
+	if !ok {
+		m = new(Meta)
+		w.meta[id] = m
+	}
+	return m
+}
+
+// 移除空的交易标注。
+func (w *Watcher) tidyMeta(id tx.TxID) {
+	if m := w.meta[id]; *m == (Meta{}) {
+		delete(w.meta, id)
+	}
+}
+
+// 待确认交易花费的输入集。
+func (w *Watcher) pendingVins() map[tx.Vin]bool {
+	used := make(map[tx.Vin]bool)
+
+	for _, t := range w.pending {
+		for _, in := range t.Body.Vins() {
+			used[in] = true
+		}
+	}
+	return used
+}
+
+// 移除已被区块确认或与之冲突的待确认交易。
+func (w *Watcher) prunePending(b *block.Block) {
+	if len(w.pending) == 0 {
+		return
+	}
+	ids := make(map[tx.TxID]bool)
+	vins := make(map[tx.Vin]bool)
+
+	for _, t := range b.Txs {
+		ids[t.ID()] = true
+		for _, in := range t.Body.Vins() {
+			vins[in] = true
+		}
+	}
+	keep := w.pending[:0]
+
+	for _, t := range w.pending {
+		if ids[t.ID()] || conflicts(t, vins) {
+			continue
+		}
+		keep = append(keep, t)
+	}
+	w.pending = keep
+}
+
+// 交易是否花费了给定输入集中的输入。
+func conflicts(t *tx.Tx, vins map[tx.Vin]bool) bool {
+	for _, in := range t.Body.Vins() {
+		if vins[in] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,150 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package wallet
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/cxio/cbase/block"
+	"github.com/cxio/cbase/tx"
+)
+
+// 连接测试链的钱包。
+func testWatcher(t *testing.T) *Watcher {
+	w := NewWatcher(addrMe)
+
+	for _, b := range testChain() {
+		if err := w.Connect(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return w
+}
+
+func TestRecords(t *testing.T) {
+	w := testWatcher(t)
+	rs := w.Records()
+
+	if len(rs) != 2 {
+		t.Fatalf("got %d records, want 2", len(rs))
+	}
+	if rs[0].Kind != KindIncoming || rs[0].Received != 5000 {
+		t.Errorf("record 0: %+v", rs[0])
+	}
+	// 5000 = 3000 + 1900 + 100
+	if rs[1].Kind != KindOutgoing || rs[1].Sent != 3000 || rs[1].Fee != 100 {
+		t.Errorf("record 1: %+v", rs[1])
+	}
+}
+
+func TestIncomingWithChange(t *testing.T) {
+	w := NewWatcher(addrMe)
+
+	// 他人支付 100 给本钱包，找零 900 给自己
+	b := &block.Block{Height: 100, Txs: []*tx.Tx{
+		testTx(1, []tx.Vin{testVin(50, 0, 0)}, coinOut(addrMe, 100), coinOut(addrOther, 900)),
+	}}
+	if err := w.Connect(b); err != nil {
+		t.Fatal(err)
+	}
+	r := w.Records()[0]
+	if r.Sent != 0 || r.Fee != 0 || r.Kind != KindIncoming || r.Received != 100 {
+		t.Errorf("got %+v", r)
+	}
+}
+
+func TestPending(t *testing.T) {
+	w := testWatcher(t)
+
+	// 自我转账：1900 → 1850
+	self := testTx(5, []tx.Vin{testVin(101, 0, 1)}, coinOut(addrMe, 1850))
+	if !w.AddPending(self) || w.AddPending(self) {
+		t.Fatal("AddPending: want added once")
+	}
+	if w.AddPending(testTx(6, nil, coinOut(addrOther, 1))) {
+		t.Error("AddPending: unrelated tx added")
+	}
+	b := w.Balances()
+	if b.Confirmed != 1900 || b.Spendable() != 0 || b.Pending() != 1850 {
+		t.Errorf("balances: %+v", b)
+	}
+	if len(w.Unspent()) != 0 {
+		t.Error("Unspent: includes pending-spent output")
+	}
+	rs := w.Records()
+	if r := rs[len(rs)-1]; !r.Pending || r.Kind != KindSelf || r.Fee != 50 {
+		t.Errorf("pending record: %+v", r)
+	}
+	if !w.RemovePending(self.ID()) || len(w.Pending()) != 0 {
+		t.Error("RemovePending failed")
+	}
+}
+
+func TestExport(t *testing.T) {
+	w := testWatcher(t)
+	id := w.History()[1].TxID
+
+	w.SetLabel(id, "rent")
+	w.SetNote(id, "march, 2 rooms")
+
+	var buf bytes.Buffer
+	if err := w.ExportCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 || rows[2][3] != "outgoing" || rows[2][12] != "rent" || rows[2][13] != "march, 2 rooms" {
+		t.Errorf("csv: %q", rows)
+	}
+	buf.Reset()
+	if err := w.ExportJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var rs []Record
+	if err := json.Unmarshal(buf.Bytes(), &rs); err != nil {
+		t.Fatal(err)
+	}
+	if len(rs) != 2 || rs[1].TxID != id || rs[1].Kind != KindOutgoing {
+		t.Errorf("json: %+v", rs)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	w := testWatcher(t)
+	w.AddPending(testTx(5, nil, coinOut(addrMe, 300)))
+	w.SetLabel(w.History()[0].TxID, "salary")
+
+	path := filepath.Join(t.TempDir(), "wallet.json")
+	if err := w.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	// 覆盖已有文件
+	if err := w.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Balances() != w.Balances() {
+		t.Errorf("balances: got %+v, want %+v", got.Balances(), w.Balances())
+	}
+	if !got.Watched(addrMe) || got.Meta(w.History()[0].TxID).Label != "salary" {
+		t.Error("addresses or labels not restored")
+	}
+	// 载入后仍可断开区块
+	chain := testChain()
+	if err := got.Disconnect(chain[1]); err != nil {
+		t.Fatal(err)
+	}
+	if got.Balance() != 5000 {
+		t.Errorf("balance after disconnect: got %d, want 5000", got.Balance())
+	}
+}
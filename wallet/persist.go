@@ -0,0 +1,92 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package wallet
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/cxio/cbase"
+	"github.com/cxio/cbase/cerror"
+	"github.com/cxio/cbase/tx"
+)
+
+// 钱包文件格式版本。
+const fileVersion = 1
+
+// 钱包文件版本不支持。
+var ErrFileVersion = cerror.New(7301, "不支持的钱包文件版本")
+
+// 钱包文件内容。
+type walletFile struct {
+	Version int               `json:"version"`
+	Addrs   []PKAddr          `json:"addrs"`
+	Unspent []*Output         `json:"unspent"`
+	Blocks  []undo            `json:"blocks"`
+	History []*Entry          `json:"history"`
+	Pending [][]byte          `json:"pending"` // 交易的序列化数据
+	Meta    map[tx.TxID]*Meta `json:"meta"`
+}
+
+// Save 保存钱包状态到文件。
+// 原子地替换目标文件，中途崩溃不会损坏已有的钱包文件。
+func (w *Watcher) Save(path string) error {
+	w.mu.RLock()
+	f := walletFile{
+		Version: fileVersion,
+		Blocks:  w.blocks,
+		History: w.history,
+		Meta:    w.meta,
+	}
+	for a := range w.addrs {
+		f.Addrs = append(f.Addrs, PKAddr(a))
+	}
+	for _, o := range w.unspent {
+		f.Unspent = append(f.Unspent, o)
+	}
+	for _, t := range w.pending {
+		f.Pending = append(f.Pending, t.Bytes())
+	}
+	data, err := json.Marshal(&f)
+	w.mu.RUnlock()
+
+	if err != nil {
+		return err
+	}
+	return cbase.WriteFileAtomic(path, data, 0600)
+}
+
+// Load 从文件载入钱包状态。
+func Load(path string) (*Watcher, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f walletFile
+
+	if err = json.Unmarshal(data, &f); err != nil {
+		return nil, err
+	}
+	if f.Version != fileVersion {
+		return nil, ErrFileVersion.With(f.Version)
+	}
+	w := NewWatcher(f.Addrs...)
+	w.blocks = f.Blocks
+	w.history = f.History
+
+	for _, o := range f.Unspent {
+		w.unspent[o.ID] = o
+	}
+	for _, b := range f.Pending {
+		t, err := tx.Decode(b)
+		if err != nil {
+			return nil, err
+		}
+		w.pending = append(w.pending, t)
+	}
+	if f.Meta != nil {
+		w.meta = f.Meta
+	}
+	return w, nil
+}
@@ -0,0 +1,278 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+// Package wallet 钱包相关的基础组件：选币、地址监视、交易历史等。
+package wallet
+
+import (
+	"math/rand"
+	"sort"
+
+	"github.com/cxio/cbase/cerror"
+	"github.com/cxio/cbase/tx"
+)
+
+// 选币算法名称。
+const (
+	AlgoBnB      = "bnb"      // 分支定界（精确匹配，无找零）
+	AlgoKnapsack = "knapsack" // 随机背包近似
+	AlgoLargest  = "largest"  // 大额优先
+)
+
+const (
+	// 分支定界的搜索步数上限。
+	bnbMaxTries = 100000
+
+	// 背包近似的迭代次数。
+	knapsackRounds = 1000
+)
+
+var (
+	// 余额不足。
+	ErrInsufficient = cerror.New(7001, "可用余额不足")
+
+	// 目标金额无效。
+	ErrTarget = cerror.New(7002, "目标金额无效")
+)
+
+// UTXO 可花费的币金输出。
+type UTXO struct {
+	ID   tx.Vin   // 输入源索引
+	Coin *tx.Coin // 币金输出
+}
+
+// FeePolicy 手续费策略。
+// 交易费用 = Base + PerInput*输入数 + PerOutput*输出数。
+type FeePolicy struct {
+	Base      int64 // 基础费用
+	PerInput  int64 // 每个输入的费用
+	PerOutput int64 // 每个输出的费用
+	Dust      int64 // 尘额阈值，不足此值的找零不创建而并入手续费
+}
+
+// Selection 选币结果。
+type Selection struct {
+	Inputs    []UTXO // 选中的输入
+	Fee       int64  // 手续费
+	Change    int64  // 找零金额，0 表示无找零输出
+	Algorithm string // 采用的算法
+}
+
+// Total 输入合计。
+func (s *Selection) Total() int64 {
+	return sumAmount(s.Inputs)
+}
+
+// Select 选择支付目标金额的输入集。
+// 依次尝试：分支定界（精确匹配，无找零）、随机背包近似、大额优先。
+// 找零低于尘额阈值时不创建找零输出，差额并入手续费。
+// seed 为随机种子，相同输入和种子的结果确定。
+func Select(utxos []UTXO, target int64, fp FeePolicy, seed int64) (*Selection, error) {
+	if target <= 0 {
+		return nil, ErrTarget
+	}
+	// 有效值：扣除自身输入费用后的金额，不为正的输出不参与
+	pool := make([]UTXO, 0, len(utxos))
+
+	for _, u := range utxos {
+		if u.Coin != nil && u.Coin.Amount > fp.PerInput {
+			pool = append(pool, u)
+		}
+	}
+	sort.SliceStable(pool, func(i, j int) bool {
+		return pool[i].Coin.Amount > pool[j].Coin.Amount
+	})
+	// 无找零时的有效目标，以及找零的代价
+	need := target + fp.Base + fp.PerOutput
+	cost := fp.PerOutput + fp.Dust
+
+	if sumEffective(pool, fp) < need {
+		return nil, ErrInsufficient
+	}
+	if in := selectBnB(pool, fp, need, cost); in != nil {
+		return finish(in, target, fp, AlgoBnB), nil
+	}
+	rnd := rand.New(rand.NewSource(seed))
+
+	if in := selectKnapsack(pool, fp, need, cost, rnd); in != nil {
+		return finish(in, target, fp, AlgoKnapsack), nil
+	}
+	return finish(selectLargest(pool, fp, need, cost), target, fp, AlgoLargest), nil
+}
+
+//
+// 私有辅助
+///////////////////////////////////////////////////////////////////////////////
+
+// 有效值。
+func effective(u UTXO, fp FeePolicy) int64 {
+	return u.Coin.Amount - fp.PerInput
+}
+
+// 有效值合计。
+func sumEffective(us []UTXO, fp FeePolicy) int64 {
+	var n int64
+	for _, u := range us {
+		n += effective(u, fp)
+	}
+	return n
+}
+
+// 金额合计。
+func sumAmount(us []UTXO) int64 {
+	var n int64
+	for _, u := range us {
+		n += u.Coin.Amount
+	}
+	return n
+}
+
+// 构造选币结果。
+// 调用者保证输入足以支付无找零时的费用。
+func finish(in []UTXO, target int64, fp FeePolicy, algo string) *Selection {
+	fee := fp.Base + fp.PerInput*int64(len(in)) + fp.PerOutput
+	rest := sumAmount(in) - target - fee
+	s := &Selection{Inputs: in, Algorithm: algo}
+
+	// 找零需额外一个输出的费用
+	if change := rest - fp.PerOutput; change >= fp.Dust && change > 0 {
+		s.Change, s.Fee = change, fee+fp.PerOutput
+	} else {
+		s.Fee = fee + rest
+	}
+	return s
+}
+
+// 分支定界选币。
+// 寻找有效值合计落在 [need, need+cost) 内的组合（无需找零），
+// 其中浪费（超出部分）最小者优先。pool 已按金额降序排列。
+// 找零代价为零时区间为空，此时只接受恰好等于 need 的组合。
+func selectBnB(pool []UTXO, fp FeePolicy, need, cost int64) []UTXO {
+	n := len(pool)
+	vals := make([]int64, n)
+	// 剩余有效值合计（用于剪枝）
+	rest := make([]int64, n+1)
+
+	for i := n - 1; i >= 0; i-- {
+		vals[i] = effective(pool[i], fp)
+		rest[i] = rest[i+1] + vals[i]
+	}
+	var best []int
+	bestWaste := int64(-1)
+	cur := make([]int, 0, n)
+	tries := 0
+
+	var walk func(i int, sum int64)
+	walk = func(i int, sum int64) {
+		if tries++; tries > bnbMaxTries || sum > need && sum >= need+cost || sum+rest[i] < need {
+			return
+		}
+		if sum >= need {
+			if w := sum - need; bestWaste < 0 || w < bestWaste {
+				best, bestWaste = append(best[:0], cur...), w
+			}
+			return
+		}
+		if i >= n {
+			return
+		}
+		// 包含当前项
+		cur = append(cur, i)
+		walk(i+1, sum+vals[i])
+		cur = cur[:len(cur)-1]
+
+		// 排除当前项；与前一个被排除项等值时跳过（等价分支）
+		j := i + 1
+		for j < n && vals[j] == vals[i] {
+			j++
+		}
+		walk(j, sum)
+	}
+	walk(0, 0)
+
+	if best == nil {
+		return nil
+	}
+	out := make([]UTXO, len(best))
+	for i, k := range best {
+		out[i] = pool[k]
+	}
+	return out
+}
+
+// 随机背包近似选币。
+// 寻找有效值合计不低于 need+cost（找零不成为尘额）的最小组合。
+// 若有单个输出即可满足且更小，则优先采用。
+func selectKnapsack(pool []UTXO, fp FeePolicy, need, cost int64, rnd *rand.Rand) []UTXO {
+	goal := need + cost
+	if sumEffective(pool, fp) < goal {
+		return nil
+	}
+	n := len(pool)
+	best := make([]bool, n)
+	for i := range best {
+		best[i] = true
+	}
+	bestSum := sumEffective(pool, fp)
+	pick := make([]bool, n)
+
+	for r := 0; r < knapsackRounds && bestSum != goal; r++ {
+		for i := range pick {
+			pick[i] = false
+		}
+		var sum int64
+		done := false
+
+		// 首轮随机选取，次轮补足未选中的
+		for pass := 0; pass < 2 && !done; pass++ {
+			for i := 0; i < n; i++ {
+				if pass == 0 && rnd.Intn(2) == 0 || pass == 1 && pick[i] {
+					continue
+				}
+				pick[i] = true
+				sum += effective(pool[i], fp)
+
+				if sum >= goal {
+					done = true
+					if sum < bestSum {
+						bestSum = sum
+						copy(best, pick)
+					}
+					// 去掉当前项继续寻找更优组合
+					pick[i] = false
+					sum -= effective(pool[i], fp)
+				}
+			}
+		}
+	}
+	// 单个输出的最小满足者
+	for i := n - 1; i >= 0; i-- {
+		if v := effective(pool[i], fp); v >= goal {
+			if v <= bestSum {
+				return []UTXO{pool[i]}
+			}
+			break
+		}
+	}
+	var out []UTXO
+	for i, ok := range best {
+		if ok {
+			out = append(out, pool[i])
+		}
+	}
+	return out
+}
+
+// 大额优先选币。
+// 依次累加直到足以支付带找零的交易，否则用尽全部（无找零）。
+func selectLargest(pool []UTXO, fp FeePolicy, need, cost int64) []UTXO {
+	var sum int64
+
+	for i, u := range pool {
+		sum += effective(u, fp)
+		if sum >= need+cost {
+			return pool[:i+1]
+		}
+	}
+	return pool
+}
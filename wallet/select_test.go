@@ -0,0 +1,93 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package wallet
+
+import (
+	"testing"
+
+	"github.com/cxio/cbase/tx"
+)
+
+// 构造测试用输出集。
+func testUTXOs(amounts ...int64) []UTXO {
+	out := make([]UTXO, len(amounts))
+	for i, a := range amounts {
+		out[i].ID[0] = byte(i)
+		out[i].Coin = &tx.Coin{Amount: a}
+	}
+	return out
+}
+
+var testFee = FeePolicy{Base: 10, PerInput: 5, PerOutput: 3, Dust: 50}
+
+func TestSelectBnB(t *testing.T) {
+	// 目标 1000：需 1000+10+3 = 1013 有效值；400+620 的有效值 395+615 = 1010 不足，
+	// 500+525 的有效值 495+520 = 1015 落入 [1013, 1066]
+	s, err := Select(testUTXOs(2000, 620, 525, 500, 400), 1000, testFee, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Algorithm != AlgoBnB || s.Change != 0 || len(s.Inputs) != 2 {
+		t.Fatalf("got %+v", s)
+	}
+	if s.Total()-s.Fee != 1000 {
+		t.Errorf("total %d - fee %d != target", s.Total(), s.Fee)
+	}
+}
+
+func TestSelectBnBBoundary(t *testing.T) {
+	// 有效值 1066 = 1013 + 53，恰为找零成为尘额阈值的边界，不属于精确匹配
+	s, err := Select(testUTXOs(1071), 1000, testFee, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Algorithm == AlgoBnB || s.Change != testFee.Dust {
+		t.Errorf("boundary: got %+v", s)
+	}
+	// 少1则无找零
+	s, err = Select(testUTXOs(1070), 1000, testFee, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Algorithm != AlgoBnB || s.Change != 0 || s.Fee != 70 {
+		t.Errorf("below boundary: got %+v", s)
+	}
+	// 无找零代价时仍接受恰好相等的组合
+	s, err = Select(testUTXOs(1000), 1000, FeePolicy{}, 1)
+	if err != nil || s.Algorithm != AlgoBnB || s.Change != 0 {
+		t.Errorf("zero cost: got %+v, %v", s, err)
+	}
+}
+
+func TestSelectChange(t *testing.T) {
+	utxos := testUTXOs(5000, 3000, 800)
+
+	for seed := int64(0); seed < 5; seed++ {
+		s, err := Select(utxos, 1000, testFee, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.Change != 0 && s.Change < testFee.Dust {
+			t.Errorf("seed %d: dust change %d", seed, s.Change)
+		}
+		if got := s.Total() - s.Fee - s.Change; got != 1000 {
+			t.Errorf("seed %d: paid %d, want 1000", seed, got)
+		}
+		// 相同种子结果确定
+		again, _ := Select(utxos, 1000, testFee, seed)
+		if len(again.Inputs) != len(s.Inputs) || again.Fee != s.Fee {
+			t.Errorf("seed %d: non-deterministic result", seed)
+		}
+	}
+}
+
+func TestSelectInsufficient(t *testing.T) {
+	if _, err := Select(testUTXOs(100, 200), 1000, testFee, 1); err != ErrInsufficient {
+		t.Errorf("got %v, want ErrInsufficient", err)
+	}
+	// 金额不足以支付自身输入费用的被忽略
+	if _, err := Select(testUTXOs(4, 5), 1, FeePolicy{PerInput: 5}, 1); err != ErrInsufficient {
+		t.Errorf("got %v, want ErrInsufficient", err)
+	}
+}
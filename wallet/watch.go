@@ -0,0 +1,342 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package wallet
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+
+	"github.com/cxio/cbase/block"
+	"github.com/cxio/cbase/cerror"
+	"github.com/cxio/cbase/paddr"
+	"github.com/cxio/cbase/tx"
+)
+
+var (
+	// 区块不连续。
+	ErrBlockOrder = cerror.New(7101, "区块与当前链端不连续")
+
+	// 非链端区块。
+	ErrNotTip = cerror.New(7102, "只能断开当前链端区块")
+)
+
+// 公钥地址引用
+type PKAddr = paddr.PKAddr
+
+// Output 支付给关注地址的输出。
+// 币金类和凭信类输出二者之一有值。
+type Output struct {
+	ID       tx.Vin     // 输出的脚本ID（被花费时即输入项）
+	TxID     tx.TxID    // 所属交易
+	Height   int        // 所在区块高度
+	Receiver PKAddr     // 接收地址
+	Coin     *tx.Coin   // 币金输出
+	Credit   *tx.Credit // 凭信输出
+}
+
+// Entry 交易历史条目。
+// 每笔涉及关注地址的交易一条。
+type Entry struct {
+	TxID       tx.TxID // 交易ID
+	Height     int     // 区块高度
+	Time       int64   // 区块时间戳（毫秒）
+	Received   int64   // 收到的币金
+	Spent      int64   // 花费的币金
+	Sent       int64   // 支付给他人的币金（仅本钱包出资的交易）
+	Fee        int64   // 手续费，仅在全部输入属于本钱包时可知
+	CreditsIn  int     // 收到的凭信数
+	CreditsOut int     // 转出的凭信数
+}
+
+// Delta 币金净变化。
+func (e *Entry) Delta() int64 {
+	return e.Received - e.Spent
+}
+
+// 区块撤销记录。
+// 字段导出以便持久化。
+type undo struct {
+	Height  int        // 区块高度
+	Hash    block.Hash // 区块哈希
+	Added   []tx.Vin   // 新增的输出
+	Spent   []*Output  // 被花费的输出
+	Entries int        // 连接前的历史条目数
+}
+
+// Watcher 只读钱包。
+// 监视一组地址，扫描区块中支付给它们的输出和花费它们的输入，
+// 维护未花费输出集、余额和交易历史。支持链重组时断开区块。
+// 注：新登记的地址只对之后连接的区块生效。
+type Watcher struct {
+	mu      sync.RWMutex
+	addrs   map[string]bool
+	unspent map[tx.Vin]*Output
+	blocks  []undo
+	history []*Entry
+	pending []*tx.Tx          // 待确认交易
+	meta    map[tx.TxID]*Meta // 交易标签和备注
+}
+
+// NewWatcher 创建只读钱包。
+func NewWatcher(addrs ...PKAddr) *Watcher {
+	w := &Watcher{
+		addrs:   make(map[string]bool),
+		unspent: make(map[tx.Vin]*Output),
+		meta:    make(map[tx.TxID]*Meta),
+	}
+	w.Watch(addrs...)
+	return w
+}
+
+// Watch 登记关注的地址。
+// 多重签名地址可通过 paddr.MulHash 获得，或使用 WatchMulti。
+func (w *Watcher) Watch(addrs ...PKAddr) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, a := range addrs {
+		w.addrs[string(a)] = true
+	}
+}
+
+// WatchMulti 登记多重签名地址。
+// 参数含义同 paddr.MulHash，返回登记的地址。
+func (w *Watcher) WatchMulti(pks, pkhs [][]byte) (PKAddr, error) {
+	addr, err := paddr.MulHash(pks, pkhs)
+	if err != nil {
+		return nil, err
+	}
+	w.Watch(addr)
+	return addr, nil
+}
+
+// Watched 是否为关注的地址。
+func (w *Watcher) Watched(addr PKAddr) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	return w.addrs[string(addr)]
+}
+
+// Tip 当前链端的高度和哈希。
+// 尚未连接区块时ok为false。
+func (w *Watcher) Tip() (height int, hash block.Hash, ok bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if len(w.blocks) == 0 {
+		return 0, hash, false
+	}
+	top := w.blocks[len(w.blocks)-1]
+
+	return top.Height, top.Hash, true
+}
+
+// Connect 连接区块。
+// 首个区块的高度不限，之后的区块须紧接当前链端。
+// 已被区块确认或与区块中交易冲突的待确认交易会被移除。
+func (w *Watcher) Connect(b *block.Block) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if n := len(w.blocks); n > 0 {
+		top := w.blocks[n-1]
+		if b.Height != top.Height+1 || b.Prev != top.Hash {
+			return ErrBlockOrder.With(b.Height)
+		}
+	}
+	u := undo{Height: b.Height, Hash: b.Hash(), Entries: len(w.history)}
+
+	for n, t := range b.Txs {
+		w.scan(b, n, t, &u)
+	}
+	w.blocks = append(w.blocks, u)
+	w.prunePending(b)
+
+	return nil
+}
+
+// Disconnect 断开当前链端区块。
+// 撤销该区块对未花费输出集和历史的全部影响。
+// 区块中的交易不会自动回到待确认集，需要时由调用者通过 AddPending 加入。
+func (w *Watcher) Disconnect(b *block.Block) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	n := len(w.blocks)
+	if n == 0 || w.blocks[n-1].Hash != b.Hash() {
+		return ErrNotTip.With(b.Height)
+	}
+	u := w.blocks[n-1]
+
+	// 先恢复再删除：同块内创建又花费的输出最终被删除
+	for _, o := range u.Spent {
+		w.unspent[o.ID] = o
+	}
+	for _, id := range u.Added {
+		delete(w.unspent, id)
+	}
+	w.history = w.history[:u.Entries]
+	w.blocks = w.blocks[:n-1]
+
+	return nil
+}
+
+// Balance 已确认的币金余额。
+// 含被待确认交易花费的部分，参见 Balances。
+func (w *Watcher) Balance() int64 {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	var sum int64
+	for _, o := range w.unspent {
+		if o.Coin != nil {
+			sum += o.Coin.Amount
+		}
+	}
+	return sum
+}
+
+// Unspent 可花费的币金输出。
+// 不含已被待确认交易花费的输出，按脚本ID排序，可直接用于 Select 选币。
+func (w *Watcher) Unspent() []UTXO {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	used := w.pendingVins()
+	var out []UTXO
+
+	for _, o := range w.unspent {
+		if o.Coin != nil && !used[o.ID] {
+			out = append(out, UTXO{ID: o.ID, Coin: o.Coin})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return bytes.Compare(out[i].ID[:], out[j].ID[:]) < 0
+	})
+	return out
+}
+
+// Credits 持有的凭信输出。
+// 按脚本ID排序。
+func (w *Watcher) Credits() []*Output {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	var out []*Output
+	for _, o := range w.unspent {
+		if o.Credit != nil {
+			out = append(out, o)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return bytes.Compare(out[i].ID[:], out[j].ID[:]) < 0
+	})
+	return out
+}
+
+// History 交易历史。
+// 按区块和交易顺序排列，返回副本。
+func (w *Watcher) History() []Entry {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	out := make([]Entry, len(w.history))
+	for i, e := range w.history {
+		out[i] = *e
+	}
+	return out
+}
+
+//
+// 私有辅助
+///////////////////////////////////////////////////////////////////////////////
+
+// 扫描一笔交易。
+// n 为交易在区块中的序位，新增和花费记录在撤销记录中。
+func (w *Watcher) scan(b *block.Block, n int, t *tx.Tx, u *undo) {
+	e := w.entry(t)
+	if e == nil {
+		return
+	}
+	e.Height, e.Time = b.Height, b.Time
+
+	for _, in := range t.Body.Vins() {
+		if o, ok := w.unspent[in]; ok {
+			delete(w.unspent, in)
+			u.Spent = append(u.Spent, o)
+		}
+	}
+	for i, v := range t.Body.Vouts() {
+		addr := v.Receiver()
+		if addr == nil || !w.addrs[string(addr)] {
+			continue
+		}
+		o := &Output{
+			ID:       b.KeyID(n, i),
+			TxID:     e.TxID,
+			Height:   b.Height,
+			Receiver: addr,
+			Coin:     v.Coin(),
+			Credit:   v.Credit(),
+		}
+		w.unspent[o.ID] = o
+		u.Added = append(u.Added, o.ID)
+	}
+	w.history = append(w.history, e)
+}
+
+// 计算交易对本钱包的影响。
+// 基于当前未花费输出集，与本钱包无关的交易返回nil。
+func (w *Watcher) entry(t *tx.Tx) *Entry {
+	e := new(Entry)
+	vins := t.Body.Vins()
+	mine := 0
+
+	for _, in := range vins {
+		o, ok := w.unspent[in]
+		if !ok {
+			continue
+		}
+		mine++
+		if o.Coin != nil {
+			e.Spent += o.Coin.Amount
+		} else {
+			e.CreditsOut++
+		}
+	}
+	var outs int64
+
+	for _, v := range t.Body.Vouts() {
+		c := v.Coin()
+		if c != nil {
+			outs += c.Amount
+		}
+		addr := v.Receiver()
+		if addr == nil {
+			continue
+		}
+		switch {
+		case !w.addrs[string(addr)]:
+			// 仅本钱包出资的交易才有支付，否则为对方的找零
+			if c != nil && mine > 0 {
+				e.Sent += c.Amount
+			}
+		case c != nil:
+			e.Received += c.Amount
+		default:
+			e.CreditsIn++
+		}
+	}
+	if mine == 0 && e.Received == 0 && e.CreditsIn == 0 {
+		return nil
+	}
+	if mine > 0 && mine == len(vins) {
+		e.Fee = e.Spent - outs
+	}
+	e.TxID = t.ID()
+
+	return e
+}
@@ -0,0 +1,116 @@
+// Copyright 2022 of chainx.zh@gmail.com, All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+package wallet
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/cxio/cbase"
+	"github.com/cxio/cbase/block"
+	"github.com/cxio/cbase/tx"
+)
+
+var (
+	addrMe    = PKAddr(bytes.Repeat([]byte{1}, 20))
+	addrOther = PKAddr(bytes.Repeat([]byte{2}, 20))
+)
+
+// 构造输入项。
+func testVin(h, n, i int) (in tx.Vin) {
+	copy(in[:], cbase.KeyID(h, n, i))
+	return
+}
+
+// 构造测试交易。
+func testTx(ts int64, vins []tx.Vin, vouts ...tx.Vout) *tx.Tx {
+	return tx.New(tx.Header{Version: tx.Version, Timestamp: ts}, tx.NewBody(vins, vouts))
+}
+
+// 币金输出。
+func coinOut(to PKAddr, amount int64) tx.Vout {
+	return tx.NewCoinOut(&tx.Coin{Receiver: to, Amount: amount})
+}
+
+// 测试用区块链：
+// 100: 收到 5000（输出0）与凭信（输出1）
+// 101: 花费 5000，支付 3000 给他人，找零 1900
+func testChain() []*block.Block {
+	b0 := &block.Block{Height: 100, Time: 1000, Txs: []*tx.Tx{
+		testTx(1, nil, coinOut(addrOther, 10)),
+		testTx(2, nil,
+			coinOut(addrMe, 5000),
+			tx.NewCreditOut(&tx.Credit{Receiver: addrMe, Description: []byte("deed")}),
+		),
+	}}
+	b1 := &block.Block{Height: 101, Prev: b0.Hash(), Time: 2000, Txs: []*tx.Tx{
+		testTx(3, []tx.Vin{testVin(100, 1, 0)}, coinOut(addrOther, 3000), coinOut(addrMe, 1900)),
+	}}
+	return []*block.Block{b0, b1}
+}
+
+func TestWatcherConnect(t *testing.T) {
+	chain := testChain()
+	w := NewWatcher(addrMe)
+
+	for _, b := range chain {
+		if err := w.Connect(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := w.Balance(); got != 1900 {
+		t.Errorf("balance: got %d, want 1900", got)
+	}
+	us := w.Unspent()
+	if len(us) != 1 || us[0].ID != testVin(101, 0, 1) {
+		t.Errorf("unspent: got %+v", us)
+	}
+	if cs := w.Credits(); len(cs) != 1 || cs[0].ID != testVin(100, 1, 1) {
+		t.Errorf("credits: got %+v", cs)
+	}
+	hs := w.History()
+	if len(hs) != 2 || hs[0].Delta() != 5000 || hs[0].CreditsIn != 1 || hs[1].Delta() != -3100 {
+		t.Errorf("history: got %+v", hs)
+	}
+	if h, _, _ := w.Tip(); h != 101 {
+		t.Errorf("tip: got %d, want 101", h)
+	}
+}
+
+func TestWatcherReorg(t *testing.T) {
+	chain := testChain()
+	w := NewWatcher(addrMe)
+
+	for _, b := range chain {
+		w.Connect(b)
+	}
+	// 非链端区块不可断开
+	if err := w.Disconnect(chain[0]); !errors.Is(err, ErrNotTip) {
+		t.Errorf("got %v, want ErrNotTip", err)
+	}
+	if err := w.Disconnect(chain[1]); err != nil {
+		t.Fatal(err)
+	}
+	if got := w.Balance(); got != 5000 {
+		t.Errorf("balance after disconnect: got %d, want 5000", got)
+	}
+	if n := len(w.History()); n != 1 {
+		t.Errorf("history after disconnect: got %d entries, want 1", n)
+	}
+	// 替代分支：101 收到 700
+	alt := &block.Block{Height: 101, Prev: chain[0].Hash(), Time: 2100, Txs: []*tx.Tx{
+		testTx(4, nil, coinOut(addrMe, 700)),
+	}}
+	if err := w.Connect(alt); err != nil {
+		t.Fatal(err)
+	}
+	if got := w.Balance(); got != 5700 {
+		t.Errorf("balance on new branch: got %d, want 5700", got)
+	}
+	// 不连续的区块被拒绝
+	if err := w.Connect(chain[1]); !errors.Is(err, ErrBlockOrder) {
+		t.Errorf("got %v, want ErrBlockOrder", err)
+	}
+}